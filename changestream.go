@@ -0,0 +1,35 @@
+package mongodb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+// ChangeStream is the stream returned by Watch. *mongo.ChangeStream
+// satisfies it.
+type ChangeStream interface {
+	Next(ctx context.Context) bool
+	TryNext(ctx context.Context) bool
+	Decode(v interface{}) error
+	Err() error
+	Close(ctx context.Context) error
+	ID() int64
+	ResumeToken() bson.Raw
+}
+
+func changeStream(cs *mongo.ChangeStream, err error) (ChangeStream, error) {
+	if err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
+
+// Watcher opens change streams. Client, Database and Collection satisfy it.
+type Watcher interface {
+	Watch(ctx context.Context, pipeline interface{}, opts ...*options.ChangeStreamOptions) (ChangeStream, error)
+}
@@ -2,20 +2,21 @@ package mongodb
 
 import (
 	"context"
-	"errors"
 
-	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
 type Collection interface {
 	Database() Database
 	Name() string
 	Drop(ctx context.Context) error
-	Indexes() mongo.IndexView
-	InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (string, error)
-	InsertMany(ctx context.Context, documents []interface{}, opts ...*options.InsertManyOptions) ([]string, error)
+	Indexes() IndexView
+	InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (interface{}, error)
+	InsertMany(ctx context.Context, documents []interface{}, opts ...*options.InsertManyOptions) ([]interface{}, error)
 	UpdateByID(ctx context.Context, id interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error)
 	UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error)
 	UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error)
@@ -23,17 +24,20 @@ type Collection interface {
 	DeleteMany(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error)
 	FindOne(ctx context.Context, filter interface{}, result interface{}, opts ...*options.FindOneOptions) error
 	Find(ctx context.Context, filter interface{}, results interface{}, opts ...*options.FindOptions) error
-	FindOneAndDelete(ctx context.Context, filter map[string]interface{}, target interface{}, opts ...*options.FindOneAndDeleteOptions) error
-	FindOneAndUpdate(ctx context.Context, filter map[string]interface{}, update interface{}, opts ...*options.FindOneAndUpdateOptions) error
-	FindOneAndReplace(ctx context.Context, filter map[string]interface{}, replace interface{}, opts ...*options.FindOneAndReplaceOptions) error
-	ReplaceOne(ctx context.Context, filter map[string]interface{}, replacement interface{}, opts ...*options.ReplaceOptions) (*mongo.UpdateResult, error)
+	FindIterator(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (Iterator, error)
+	FindEach(ctx context.Context, filter interface{}, fn func(decode func(v interface{}) error) error, opts ...*options.FindOptions) error
+	FindOneAndDelete(ctx context.Context, filter interface{}, target interface{}, opts ...*options.FindOneAndDeleteOptions) error
+	FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, target interface{}, opts ...*options.FindOneAndUpdateOptions) error
+	FindOneAndReplace(ctx context.Context, filter interface{}, replace interface{}, target interface{}, opts ...*options.FindOneAndReplaceOptions) error
+	ReplaceOne(ctx context.Context, filter interface{}, replacement interface{}, opts ...*options.ReplaceOptions) (*mongo.UpdateResult, error)
 	Aggregate(ctx context.Context, pipeline interface{}, target interface{}, opts ...*options.AggregateOptions) error
+	AggregateIterator(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (Iterator, error)
 	BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error)
-	Clone(opts ...*options.CollectionOptions) (*mongo.Collection, error)
-	CountDocuments(ctx context.Context, filter map[string]interface{}, opts ...*options.CountOptions) (int64, error)
-	Distinct(ctx context.Context, fieldName string, filter map[string]interface{}, opts ...*options.DistinctOptions) ([]interface{}, error)
+	Clone(opts ...*options.CollectionOptions) (Collection, error)
+	CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error)
+	Distinct(ctx context.Context, fieldName string, filter interface{}, opts ...*options.DistinctOptions) ([]interface{}, error)
 	EstimatedDocumentCount(ctx context.Context, opts ...*options.EstimatedDocumentCountOptions) (int64, error)
-	Watch(ctx context.Context, pipeline interface{}, opts ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error)
+	Watch(ctx context.Context, pipeline interface{}, opts ...*options.ChangeStreamOptions) (ChangeStream, error)
 }
 
 func newCollection(db *database, name string, opts ...*options.CollectionOptions) Collection {
@@ -49,6 +53,10 @@ type collection struct {
 	*mongo.Collection
 }
 
+func (coll *collection) bind(ctx context.Context) context.Context {
+	return coll.db.bind(ctx)
+}
+
 func (coll *collection) Database() Database {
 	return coll.db
 }
@@ -61,63 +69,48 @@ func (coll *collection) Drop(ctx context.Context) error {
 	return coll.Collection.Drop(ctx)
 }
 
-func (coll *collection) Indexes() mongo.IndexView {
-	return coll.Collection.Indexes()
+func (coll *collection) Indexes() IndexView {
+	return newIndexView(coll)
 }
 
-func (coll *collection) InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (string, error) {
-	res, err := coll.Collection.InsertOne(ctx, document, opts...)
+func (coll *collection) InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (interface{}, error) {
+	res, err := coll.Collection.InsertOne(coll.bind(ctx), document, opts...)
 	if err != nil {
-		return "", err
-	}
-
-	obj, ok := res.InsertedID.(primitive.ObjectID)
-	if !ok {
-		return "", errors.New("mongodb: not a valid 'primitive.ObjectID'")
+		return nil, err
 	}
-
-	return obj.Hex(), nil
+	return res.InsertedID, nil
 }
 
-func (coll *collection) InsertMany(ctx context.Context, documents []interface{}, opts ...*options.InsertManyOptions) ([]string, error) {
-	res, err := coll.Collection.InsertMany(ctx, documents, opts...)
+func (coll *collection) InsertMany(ctx context.Context, documents []interface{}, opts ...*options.InsertManyOptions) ([]interface{}, error) {
+	res, err := coll.Collection.InsertMany(coll.bind(ctx), documents, opts...)
 	if err != nil {
 		return nil, err
 	}
-
-	ids := make([]string, len(res.InsertedIDs))
-	for i, objId := range res.InsertedIDs {
-		id, ok := objId.(primitive.ObjectID)
-		if !ok {
-			return nil, errors.New("mongodb: not a valid 'primitive.ObjectID'")
-		}
-		ids[i] = id.Hex()
-	}
-	return ids, nil
+	return res.InsertedIDs, nil
 }
 
 func (coll *collection) UpdateByID(ctx context.Context, id interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
-	return coll.Collection.UpdateByID(ctx, id, update, opts...)
+	return coll.Collection.UpdateByID(coll.bind(ctx), id, update, opts...)
 }
 
 func (coll *collection) UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
-	return coll.Collection.UpdateOne(ctx, filter, update, opts...)
+	return coll.Collection.UpdateOne(coll.bind(ctx), filter, update, opts...)
 }
 
 func (coll *collection) UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
-	return coll.Collection.UpdateMany(ctx, filter, update, opts...)
+	return coll.Collection.UpdateMany(coll.bind(ctx), filter, update, opts...)
 }
 
 func (coll *collection) DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
-	return coll.Collection.DeleteOne(ctx, filter, opts...)
+	return coll.Collection.DeleteOne(coll.bind(ctx), filter, opts...)
 }
 
 func (coll *collection) DeleteMany(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
-	return coll.Collection.DeleteMany(ctx, filter, opts...)
+	return coll.Collection.DeleteMany(coll.bind(ctx), filter, opts...)
 }
 
 func (coll *collection) FindOne(ctx context.Context, filter interface{}, result interface{}, opts ...*options.FindOneOptions) error {
-	res := coll.Collection.FindOne(ctx, filter, opts...)
+	res := coll.Collection.FindOne(coll.bind(ctx), filter, opts...)
 	if err := res.Err(); err != nil {
 		return err
 	}
@@ -125,22 +118,35 @@ func (coll *collection) FindOne(ctx context.Context, filter interface{}, result
 	return res.Decode(result)
 }
 
-func (coll *collection) Find(ctx context.Context, filter interface{}, results interface{}, opts ...*options.FindOptions) (err error) {
-	cursor, err := coll.Collection.Find(ctx, filter, opts...)
+func (coll *collection) Find(ctx context.Context, filter interface{}, results interface{}, opts ...*options.FindOptions) error {
+	ctx = coll.bind(ctx)
+	cur, err := coll.Collection.Find(ctx, filter, opts...)
 	if err != nil {
 		return err
 	}
 
-	defer func() {
-		err = cursor.Close(ctx)
-	}()
+	return readAll(ctx, cur, results)
+}
+
+func (coll *collection) FindIterator(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (Iterator, error) {
+	cur, err := coll.Collection.Find(coll.bind(ctx), filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return cur, nil
+}
+
+func (coll *collection) FindEach(ctx context.Context, filter interface{}, fn func(decode func(v interface{}) error) error, opts ...*options.FindOptions) error {
+	it, err := coll.FindIterator(ctx, filter, opts...)
+	if err != nil {
+		return err
+	}
 
-	err = cursor.All(ctx, results)
-	return
+	return Each(ctx, it, fn)
 }
 
-func (coll *collection) FindOneAndDelete(ctx context.Context, filter map[string]interface{}, target interface{}, opts ...*options.FindOneAndDeleteOptions) error {
-	res := coll.Collection.FindOneAndDelete(ctx, filter, opts...)
+func (coll *collection) FindOneAndDelete(ctx context.Context, filter interface{}, target interface{}, opts ...*options.FindOneAndDeleteOptions) error {
+	res := coll.Collection.FindOneAndDelete(coll.bind(ctx), orEmpty(filter), opts...)
 	if res.Err() != nil {
 		return res.Err()
 	}
@@ -148,53 +154,90 @@ func (coll *collection) FindOneAndDelete(ctx context.Context, filter map[string]
 	return res.Decode(target)
 }
 
-func (coll *collection) FindOneAndUpdate(ctx context.Context, filter map[string]interface{}, update interface{}, opts ...*options.FindOneAndUpdateOptions) error {
-	res := coll.Collection.FindOneAndUpdate(ctx, filter, update, opts...)
-	return res.Err()
+// FindOneAndUpdate decodes the document before or after the update, as
+// selected by options.FindOneAndUpdateOptions.ReturnDocument, into target.
+// A nil target only reports the error.
+func (coll *collection) FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, target interface{}, opts ...*options.FindOneAndUpdateOptions) error {
+	res := coll.Collection.FindOneAndUpdate(coll.bind(ctx), orEmpty(filter), update, opts...)
+	if res.Err() != nil || target == nil {
+		return res.Err()
+	}
+
+	return res.Decode(target)
 }
 
-func (coll *collection) FindOneAndReplace(ctx context.Context, filter map[string]interface{}, replace interface{}, opts ...*options.FindOneAndReplaceOptions) error {
-	res := coll.Collection.FindOneAndReplace(ctx, filter, replace, opts...)
-	return res.Err()
+// FindOneAndReplace decodes the document before or after the replacement,
+// as selected by options.FindOneAndReplaceOptions.ReturnDocument, into
+// target. A nil target only reports the error.
+func (coll *collection) FindOneAndReplace(ctx context.Context, filter interface{}, replace interface{}, target interface{}, opts ...*options.FindOneAndReplaceOptions) error {
+	res := coll.Collection.FindOneAndReplace(coll.bind(ctx), orEmpty(filter), replace, opts...)
+	if res.Err() != nil || target == nil {
+		return res.Err()
+	}
+
+	return res.Decode(target)
 }
 
-func (coll *collection) ReplaceOne(ctx context.Context, filter map[string]interface{}, replacement interface{}, opts ...*options.ReplaceOptions) (*mongo.UpdateResult, error) {
-	return coll.Collection.ReplaceOne(ctx, filter, replacement, opts...)
+func (coll *collection) ReplaceOne(ctx context.Context, filter interface{}, replacement interface{}, opts ...*options.ReplaceOptions) (*mongo.UpdateResult, error) {
+	return coll.Collection.ReplaceOne(coll.bind(ctx), orEmpty(filter), replacement, opts...)
 }
 
-func (coll *collection) Aggregate(ctx context.Context, pipeline interface{}, target interface{}, opts ...*options.AggregateOptions) (err error) {
-	cursor, err := coll.Collection.Aggregate(ctx, pipeline, opts...)
+func (coll *collection) Aggregate(ctx context.Context, pipeline interface{}, target interface{}, opts ...*options.AggregateOptions) error {
+	ctx = coll.bind(ctx)
+	cur, err := coll.Collection.Aggregate(ctx, pipeline, opts...)
 	if err != nil {
-		return
+		return err
 	}
 
-	defer func() {
-		err = cursor.Close(ctx)
-	}()
+	return readAll(ctx, cur, target)
+}
 
-	return cursor.All(ctx, target)
+func (coll *collection) AggregateIterator(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (Iterator, error) {
+	cur, err := coll.Collection.Aggregate(coll.bind(ctx), pipeline, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return cur, nil
 }
 
 func (coll *collection) BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
-	return coll.Collection.BulkWrite(ctx, models, opts...)
+	return coll.Collection.BulkWrite(coll.bind(ctx), models, opts...)
 }
 
-func (coll *collection) Clone(opts ...*options.CollectionOptions) (*mongo.Collection, error) {
-	return coll.Collection.Clone(opts...)
+func (coll *collection) Clone(opts ...*options.CollectionOptions) (Collection, error) {
+	c, err := coll.Collection.Clone(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &collection{
+		db:         coll.db,
+		Collection: c,
+	}, nil
 }
 
-func (coll *collection) CountDocuments(ctx context.Context, filter map[string]interface{}, opts ...*options.CountOptions) (int64, error) {
-	return coll.Collection.CountDocuments(ctx, filter, opts...)
+func (coll *collection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	return coll.Collection.CountDocuments(coll.bind(ctx), orEmpty(filter), opts...)
 }
 
-func (coll *collection) Distinct(ctx context.Context, fieldName string, filter map[string]interface{}, opts ...*options.DistinctOptions) ([]interface{}, error) {
-	return coll.Collection.Distinct(ctx, fieldName, filter, opts...)
+func (coll *collection) Distinct(ctx context.Context, fieldName string, filter interface{}, opts ...*options.DistinctOptions) ([]interface{}, error) {
+	return coll.Collection.Distinct(coll.bind(ctx), fieldName, orEmpty(filter), opts...)
 }
 
 func (coll *collection) EstimatedDocumentCount(ctx context.Context, opts ...*options.EstimatedDocumentCountOptions) (int64, error) {
 	return coll.Collection.EstimatedDocumentCount(ctx, opts...)
 }
 
-func (coll *collection) Watch(ctx context.Context, pipeline interface{}, opts ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error) {
-	return coll.Collection.Watch(ctx, pipeline, opts...)
+func (coll *collection) Watch(ctx context.Context, pipeline interface{}, opts ...*options.ChangeStreamOptions) (ChangeStream, error) {
+	return changeStream(coll.Collection.Watch(ctx, pipeline, opts...))
+}
+
+// orEmpty turns a nil filter into an empty one. These methods used to take a
+// map[string]interface{}, for which nil matched every document; the driver
+// rejects a nil interface{} filter.
+func orEmpty(filter interface{}) interface{} {
+	if filter == nil {
+		return bson.D{}
+	}
+	return filter
 }
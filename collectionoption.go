@@ -0,0 +1,132 @@
+package mongodb
+
+import (
+	"reflect"
+
+	"github.com/subratohld/mongodb/internal/structtag"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CollectionOption configures a collection handle returned by
+// Database.CollectionWith.
+type CollectionOption func(*collectionConfig)
+
+type collectionConfig struct {
+	driverOpts []*options.CollectionOptions
+	createdAt  string
+	updatedAt  string
+	version    string
+	deletedAt  string
+	// interceptors wrap the collection before any other behaviour, so they
+	// see the operations that reach the server.
+	interceptors []Interceptor
+}
+
+func newCollectionConfig(opts ...CollectionOption) *collectionConfig {
+	cfg := &collectionConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithCollectionOptions sets driver collection options such as the read
+// preference or write concern.
+func WithCollectionOptions(opts ...*options.CollectionOptions) CollectionOption {
+	return func(cfg *collectionConfig) {
+		cfg.driverOpts = append(cfg.driverOpts, opts...)
+	}
+}
+
+// WithTimestamps stamps the current time into the createdAt field when a
+// document is inserted and into the updatedAt field on every write. An empty
+// name leaves that field alone.
+func WithTimestamps(createdAt, updatedAt string) CollectionOption {
+	return func(cfg *collectionConfig) {
+		cfg.createdAt = createdAt
+		cfg.updatedAt = updatedAt
+	}
+}
+
+// WithVersion enables optimistic locking on the numeric field: inserts start
+// it at 1 and every update increments it. Replacements, and updates whose
+// filter names the field at the top level or in a top-level $and, only
+// apply while the stored version is the one given, and otherwise fail with
+// a *VersionConflictError.
+func WithVersion(field string) CollectionOption {
+	return func(cfg *collectionConfig) {
+		cfg.version = field
+	}
+}
+
+// WithSoftDelete turns deletes into updates setting field, "deletedAt" if
+// empty, to the deletion time. Reads, updates and replacements skip deleted
+// documents unless the context says otherwise; see WithDeleted and
+// OnlyDeleted. The collection implements SoftDeleter to restore or purge
+// deleted documents. EstimatedDocumentCount, BulkWrite and Watch are not
+// affected.
+func WithSoftDelete(field string) CollectionOption {
+	return func(cfg *collectionConfig) {
+		if field == "" {
+			field = "deletedAt"
+		}
+		cfg.deletedAt = field
+	}
+}
+
+// WithCollectionInterceptors adds interceptors to the collection, after any
+// registered on the client with WithInterceptors.
+func WithCollectionInterceptors(interceptors ...Interceptor) CollectionOption {
+	return func(cfg *collectionConfig) {
+		cfg.interceptors = append(cfg.interceptors, interceptors...)
+	}
+}
+
+// WithStructTags configures timestamps and versioning from the fields of
+// the struct doc, or the struct it points to, that are tagged
+// `mongodb:"createdAt"`, `mongodb:"updatedAt"` or `mongodb:"version"`.
+func WithStructTags(doc interface{}) CollectionOption {
+	return func(cfg *collectionConfig) {
+		t := reflect.TypeOf(doc)
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct {
+			return
+		}
+
+		for _, f := range structtag.Fields(t) {
+			for _, opt := range f.Options() {
+				switch opt {
+				case "createdAt":
+					cfg.createdAt = f.Name
+				case "updatedAt":
+					cfg.updatedAt = f.Name
+				case "version":
+					cfg.version = f.Name
+				}
+			}
+		}
+	}
+}
+
+// WrapCollection applies the behaviours configured by opts, such as
+// timestamps, versioning, soft deletes and interceptors, to coll. Driver
+// options are ignored. It lets other Collection implementations offer the
+// same options as Database.CollectionWith.
+func WrapCollection(coll Collection, opts ...CollectionOption) Collection {
+	return newCollectionConfig(opts...).wrap(coll)
+}
+
+func (cfg *collectionConfig) wrap(coll Collection) Collection {
+	if len(cfg.interceptors) > 0 {
+		coll = &interceptedCollection{Collection: coll, interceptors: cfg.interceptors}
+	}
+	if cfg.createdAt != "" || cfg.updatedAt != "" || cfg.version != "" {
+		coll = &stampedCollection{Collection: coll, cfg: *cfg}
+	}
+	if cfg.deletedAt != "" {
+		coll = &softDeleteCollection{Collection: coll, field: cfg.deletedAt}
+	}
+	return coll
+}
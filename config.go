@@ -0,0 +1,271 @@
+package mongodb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
+	"gopkg.in/yaml.v3"
+)
+
+// Config describes a client in a form that can be loaded from JSON or YAML
+// files or from environment variables.
+type Config struct {
+	URI                    string   `json:"uri" yaml:"uri" env:"URI"`
+	Username               string   `json:"username" yaml:"username" env:"USERNAME"`
+	Password               string   `json:"password" yaml:"password" env:"PASSWORD"`
+	AuthSource             string   `json:"authSource" yaml:"authSource" env:"AUTH_SOURCE"`
+	AuthMechanism          string   `json:"authMechanism" yaml:"authMechanism" env:"AUTH_MECHANISM"`
+	AppName                string   `json:"appName" yaml:"appName" env:"APP_NAME"`
+	ReplicaSet             string   `json:"replicaSet" yaml:"replicaSet" env:"REPLICA_SET"`
+	ReadPreference         string   `json:"readPreference" yaml:"readPreference" env:"READ_PREFERENCE"`
+	Compressors            []string `json:"compressors" yaml:"compressors" env:"COMPRESSORS"`
+	MinPoolSize            uint64   `json:"minPoolSize" yaml:"minPoolSize" env:"MIN_POOL_SIZE"`
+	MaxPoolSize            uint64   `json:"maxPoolSize" yaml:"maxPoolSize" env:"MAX_POOL_SIZE"`
+	ConnectTimeout         Duration `json:"connectTimeout" yaml:"connectTimeout" env:"CONNECT_TIMEOUT"`
+	ServerSelectionTimeout Duration `json:"serverSelectionTimeout" yaml:"serverSelectionTimeout" env:"SERVER_SELECTION_TIMEOUT"`
+	SocketTimeout          Duration `json:"socketTimeout" yaml:"socketTimeout" env:"SOCKET_TIMEOUT"`
+	MaxConnIdleTime        Duration `json:"maxConnIdleTime" yaml:"maxConnIdleTime" env:"MAX_CONN_IDLE_TIME"`
+}
+
+// Duration is a time.Duration written as a string such as "5s" in
+// configuration files and environment variables.
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// ConfigError reports an invalid configuration value. Field is the name used
+// in the source the value came from: the JSON/YAML key or the environment
+// variable.
+type ConfigError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("mongodb: invalid config field %q: %s", e.Field, e.Reason)
+}
+
+var compressors = map[string]bool{"snappy": true, "zlib": true, "zstd": true}
+
+// Validate checks the configuration and returns a *ConfigError naming the
+// first offending field.
+func (c Config) Validate() error {
+	switch {
+	case c.URI == "":
+		return &ConfigError{Field: "uri", Reason: "must be set"}
+	case !strings.HasPrefix(c.URI, "mongodb://") && !strings.HasPrefix(c.URI, "mongodb+srv://"):
+		return &ConfigError{Field: "uri", Reason: `must start with "mongodb://" or "mongodb+srv://"`}
+	case c.Password != "" && c.Username == "":
+		return &ConfigError{Field: "username", Reason: "must be set when a password is given"}
+	case c.MaxPoolSize > 0 && c.MinPoolSize > c.MaxPoolSize:
+		return &ConfigError{Field: "minPoolSize", Reason: fmt.Sprintf("%d exceeds maxPoolSize %d", c.MinPoolSize, c.MaxPoolSize)}
+	}
+
+	if c.ReadPreference != "" {
+		if _, err := readpref.ModeFromString(c.ReadPreference); err != nil {
+			return &ConfigError{Field: "readPreference", Reason: err.Error()}
+		}
+	}
+
+	for _, comp := range c.Compressors {
+		if !compressors[comp] {
+			return &ConfigError{Field: "compressors", Reason: fmt.Sprintf("unknown compressor %q", comp)}
+		}
+	}
+
+	durations := []struct {
+		field string
+		value Duration
+	}{
+		{"connectTimeout", c.ConnectTimeout},
+		{"serverSelectionTimeout", c.ServerSelectionTimeout},
+		{"socketTimeout", c.SocketTimeout},
+		{"maxConnIdleTime", c.MaxConnIdleTime},
+	}
+	for _, d := range durations {
+		if d.value < 0 {
+			return &ConfigError{Field: d.field, Reason: "must not be negative"}
+		}
+	}
+	return nil
+}
+
+// Options maps the configuration onto client options. It does not validate
+// the configuration.
+func (c Config) Options() []Option {
+	opts := []Option{WithURI(c.URI)}
+	if c.Username != "" {
+		opts = append(opts, WithCredential(c.credential()))
+	}
+	if c.AppName != "" {
+		opts = append(opts, WithAppName(c.AppName))
+	}
+	if c.ReplicaSet != "" {
+		opts = append(opts, WithRawClientOptions(options.Client().SetReplicaSet(c.ReplicaSet)))
+	}
+	if c.ReadPreference != "" {
+		mode, _ := readpref.ModeFromString(c.ReadPreference)
+		rp, _ := readpref.New(mode)
+		opts = append(opts, WithReadPreference(rp))
+	}
+	if len(c.Compressors) > 0 {
+		opts = append(opts, WithCompressors(c.Compressors...))
+	}
+	if c.MinPoolSize > 0 || c.MaxPoolSize > 0 {
+		opts = append(opts, WithRawClientOptions(poolOptions(c.MinPoolSize, c.MaxPoolSize)))
+	}
+	return append(opts, WithTimeouts(Timeouts{
+		Connect:         time.Duration(c.ConnectTimeout),
+		ServerSelection: time.Duration(c.ServerSelectionTimeout),
+		Socket:          time.Duration(c.SocketTimeout),
+		MaxConnIdle:     time.Duration(c.MaxConnIdleTime),
+	}))
+}
+
+// credential merges the configured credential into the one given in the
+// URI, so that an authSource or mechanism set only in the URI is kept.
+func (c Config) credential() options.Credential {
+	var cred options.Credential
+	if cs, err := connstring.Parse(c.URI); err == nil {
+		cred.AuthSource = cs.AuthSource
+		cred.AuthMechanism = cs.AuthMechanism
+		cred.AuthMechanismProperties = cs.AuthMechanismProperties
+	}
+	cred.Username = c.Username
+	cred.Password = c.Password
+	cred.PasswordSet = c.Password != ""
+	if c.AuthSource != "" {
+		cred.AuthSource = c.AuthSource
+	}
+	if c.AuthMechanism != "" {
+		cred.AuthMechanism = c.AuthMechanism
+	}
+	return cred
+}
+
+// poolOptions sets only the pool bounds that were configured, so a bound
+// given in the URI is not reset to zero.
+func poolOptions(min, max uint64) *options.ClientOptions {
+	opts := options.Client()
+	if min > 0 {
+		opts.SetMinPoolSize(min)
+	}
+	if max > 0 {
+		opts.SetMaxPoolSize(max)
+	}
+	return opts
+}
+
+// NewClientFromConfig validates cfg and connects with it. opts are applied
+// after the configuration and override it.
+func NewClientFromConfig(ctx context.Context, cfg Config, opts ...Option) (Client, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return NewClient(ctx, append(cfg.Options(), opts...)...)
+}
+
+// LoadConfigFromFile reads a JSON file, or a YAML file when the extension is
+// .yaml or .yml, and validates it.
+func LoadConfigFromFile(path string) (Config, error) {
+	var cfg Config
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &cfg)
+	default:
+		err = json.Unmarshal(data, &cfg)
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("mongodb: parsing config %s: %w", path, err)
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// LoadConfigFromEnv builds a Config from environment variables named after
+// the env tags of Config, e.g. MONGO_URI and MONGO_MAX_POOL_SIZE for the
+// prefix "MONGO". Lists are comma separated and durations use the
+// time.ParseDuration syntax.
+func LoadConfigFromEnv(prefix string) (Config, error) {
+	if prefix != "" && !strings.HasSuffix(prefix, "_") {
+		prefix += "_"
+	}
+
+	var cfg Config
+	rv := reflect.ValueOf(&cfg).Elem()
+	rt := rv.Type()
+	envNames := make(map[string]string, rt.NumField())
+	for i := 0; i < rt.NumField(); i++ {
+		name := prefix + rt.Field(i).Tag.Get("env")
+		envNames[rt.Field(i).Tag.Get("json")] = name
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			continue
+		}
+		if err := setEnvField(rv.Field(i), value); err != nil {
+			return cfg, &ConfigError{Field: name, Reason: err.Error()}
+		}
+	}
+
+	// Validate names fields by their JSON key; report the variable instead.
+	err := cfg.Validate()
+	if cerr, ok := err.(*ConfigError); ok {
+		return cfg, &ConfigError{Field: envNames[cerr.Field], Reason: cerr.Reason}
+	}
+	return cfg, err
+}
+
+var durationType = reflect.TypeOf(Duration(0))
+
+func setEnvField(field reflect.Value, value string) error {
+	if field.Type() == durationType {
+		return field.Addr().Interface().(*Duration).UnmarshalText([]byte(value))
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a non-negative integer", value)
+		}
+		field.SetUint(n)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	}
+	return nil
+}
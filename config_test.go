@@ -0,0 +1,141 @@
+package mongodb
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+func TestLoadConfigFromEnv(t *testing.T) {
+	t.Setenv("MONGO_URI", "mongodb://localhost:27017")
+	t.Setenv("MONGO_USERNAME", "app")
+	t.Setenv("MONGO_PASSWORD", "secret")
+	t.Setenv("MONGO_MAX_POOL_SIZE", "50")
+	t.Setenv("MONGO_COMPRESSORS", "zstd, snappy")
+	t.Setenv("MONGO_CONNECT_TIMEOUT", "3s")
+	t.Setenv("MONGO_READ_PREFERENCE", "secondaryPreferred")
+
+	cfg, err := LoadConfigFromEnv("MONGO")
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv: %v", err)
+	}
+
+	want := Config{
+		URI:            "mongodb://localhost:27017",
+		Username:       "app",
+		Password:       "secret",
+		MaxPoolSize:    50,
+		Compressors:    []string{"zstd", "snappy"},
+		ConnectTimeout: Duration(3 * time.Second),
+		ReadPreference: "secondaryPreferred",
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("got %+v, want %+v", cfg, want)
+	}
+
+	got := options.MergeClientOptions(newClientConfig(cfg.Options()...).clientOpts...)
+	if *got.MaxPoolSize != 50 || got.MinPoolSize != nil {
+		t.Errorf("pool size = %v..%v", got.MinPoolSize, *got.MaxPoolSize)
+	}
+	if got.Auth == nil || got.Auth.Username != "app" || got.Auth.Password != "secret" {
+		t.Errorf("Auth = %+v", got.Auth)
+	}
+	if *got.ConnectTimeout != 3*time.Second || got.ReadPreference.Mode() != readpref.SecondaryPreferredMode {
+		t.Errorf("ConnectTimeout = %v, ReadPreference = %v", *got.ConnectTimeout, got.ReadPreference.Mode())
+	}
+}
+
+func TestLoadConfigFromEnvInvalidValue(t *testing.T) {
+	t.Setenv("APP_URI", "mongodb://localhost:27017")
+	t.Setenv("APP_MIN_POOL_SIZE", "-1")
+
+	_, err := LoadConfigFromEnv("APP_")
+	var cerr *ConfigError
+	if !errors.As(err, &cerr) || cerr.Field != "APP_MIN_POOL_SIZE" {
+		t.Errorf("err = %v, want a ConfigError for APP_MIN_POOL_SIZE", err)
+	}
+}
+
+func TestLoadConfigFromEnvValidation(t *testing.T) {
+	t.Setenv("APP_URI", "mongodb://localhost:27017")
+	t.Setenv("APP_MIN_POOL_SIZE", "10")
+	t.Setenv("APP_MAX_POOL_SIZE", "5")
+
+	_, err := LoadConfigFromEnv("APP")
+	var cerr *ConfigError
+	if !errors.As(err, &cerr) || cerr.Field != "APP_MIN_POOL_SIZE" {
+		t.Errorf("err = %v, want a ConfigError for APP_MIN_POOL_SIZE", err)
+	}
+}
+
+func TestConfigCredentialKeepsURIAuth(t *testing.T) {
+	cfg := Config{URI: "mongodb://localhost:27017/?authSource=admin&authMechanism=SCRAM-SHA-256", Username: "orders", Password: "secret"}
+
+	opts := options.MergeClientOptions(newClientConfig(cfg.Options()...).clientOpts...)
+	want := options.Credential{AuthMechanism: "SCRAM-SHA-256", AuthSource: "admin", Username: "orders", Password: "secret", PasswordSet: true}
+	if opts.Auth == nil || !reflect.DeepEqual(*opts.Auth, want) {
+		t.Errorf("Auth = %+v, want %+v", opts.Auth, want)
+	}
+}
+
+func TestLoadConfigFromFile(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "mongo.yaml")
+	jsonPath := filepath.Join(dir, "mongo.json")
+
+	yamlDoc := "uri: mongodb://db:27017\nappName: orders\nsocketTimeout: 10s\ncompressors: [zlib]\n"
+	jsonDoc := `{"uri": "mongodb://db:27017", "appName": "orders", "socketTimeout": "10s", "compressors": ["zlib"]}`
+	if err := os.WriteFile(yamlPath, []byte(yamlDoc), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(jsonPath, []byte(jsonDoc), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	want := Config{URI: "mongodb://db:27017", AppName: "orders", SocketTimeout: Duration(10 * time.Second), Compressors: []string{"zlib"}}
+	for _, path := range []string{yamlPath, jsonPath} {
+		cfg, err := LoadConfigFromFile(path)
+		if err != nil {
+			t.Fatalf("LoadConfigFromFile(%s): %v", path, err)
+		}
+		if !reflect.DeepEqual(cfg, want) {
+			t.Errorf("%s: got %+v, want %+v", path, cfg, want)
+		}
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := Config{URI: "mongodb://localhost:27017"}
+
+	tests := []struct {
+		field  string
+		mutate func(*Config)
+	}{
+		{"uri", func(c *Config) { c.URI = "" }},
+		{"uri", func(c *Config) { c.URI = "http://localhost" }},
+		{"username", func(c *Config) { c.Password = "secret" }},
+		{"minPoolSize", func(c *Config) { c.MinPoolSize, c.MaxPoolSize = 10, 5 }},
+		{"readPreference", func(c *Config) { c.ReadPreference = "closest" }},
+		{"compressors", func(c *Config) { c.Compressors = []string{"gzip"} }},
+		{"socketTimeout", func(c *Config) { c.SocketTimeout = Duration(-time.Second) }},
+	}
+
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	for _, tt := range tests {
+		cfg := valid
+		tt.mutate(&cfg)
+
+		var cerr *ConfigError
+		if err := cfg.Validate(); !errors.As(err, &cerr) || cerr.Field != tt.field {
+			t.Errorf("Validate() = %v, want a ConfigError for %q", err, tt.field)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ChangeEvent is a change stream event whose full document decodes into T.
+// FullDocument is nil for deletes, and for updates unless the stream was
+// opened with options.UpdateLookup.
+type ChangeEvent[T any] struct {
+	ID                bson.Raw            `bson:"_id"`
+	OperationType     string              `bson:"operationType"`
+	Namespace         Namespace           `bson:"ns"`
+	ClusterTime       primitive.Timestamp `bson:"clusterTime"`
+	DocumentKey       bson.D              `bson:"documentKey"`
+	FullDocument      *T                  `bson:"fullDocument,omitempty"`
+	UpdateDescription *UpdateDescription  `bson:"updateDescription,omitempty"`
+}
+
+type Namespace struct {
+	Database   string `bson:"db"`
+	Collection string `bson:"coll"`
+}
+
+type UpdateDescription struct {
+	UpdatedFields bson.D   `bson:"updatedFields"`
+	RemovedFields []string `bson:"removedFields"`
+}
+
+// ChangeStreamConsumer runs a change stream and hands each event to a
+// handler.
+type ChangeStreamConsumer[T any] interface {
+	// Run blocks until ctx is done, the stream is invalidated or handler
+	// fails. The resume token of an event is saved only after handler has
+	// returned nil for it, so a failed or interrupted event is delivered
+	// again by the next Run.
+	Run(ctx context.Context, handler func(ctx context.Context, event ChangeEvent[T]) error) error
+}
+
+// ResumeTokenStore persists the position of a consumer so that it can resume
+// where it left off after a restart.
+type ResumeTokenStore interface {
+	// Load returns the saved token for key, or nil when there is none.
+	Load(ctx context.Context, key string) (bson.Raw, error)
+	Save(ctx context.Context, key string, token bson.Raw) error
+}
+
+// ConsumerOption configures a ChangeStreamConsumer.
+type ConsumerOption func(*consumerConfig)
+
+type consumerConfig struct {
+	pipeline   interface{}
+	streamOpts []*options.ChangeStreamOptions
+	store      ResumeTokenStore
+	key        string
+	backoff    time.Duration
+	maxBackoff time.Duration
+}
+
+// WithConsumerPipeline sets the aggregation pipeline the stream is opened
+// with.
+func WithConsumerPipeline(pipeline interface{}) ConsumerOption {
+	return func(cfg *consumerConfig) {
+		cfg.pipeline = pipeline
+	}
+}
+
+// WithConsumerStreamOptions adds change stream options, e.g. to request
+// options.UpdateLookup. Once the consumer has a resume token, it replaces
+// any start position set here.
+func WithConsumerStreamOptions(opts ...*options.ChangeStreamOptions) ConsumerOption {
+	return func(cfg *consumerConfig) {
+		cfg.streamOpts = append(cfg.streamOpts, opts...)
+	}
+}
+
+// WithResumeTokenStore persists the consumer's resume token in store under
+// key. Consumers of different streams need different keys.
+func WithResumeTokenStore(store ResumeTokenStore, key string) ConsumerOption {
+	return func(cfg *consumerConfig) {
+		cfg.store = store
+		cfg.key = key
+	}
+}
+
+// WithRestartBackoff sets the wait before reopening a stream that failed
+// with a resumable error. The wait doubles on each consecutive failure up to
+// max, and is reset once an event has been handled.
+func WithRestartBackoff(initial, max time.Duration) ConsumerOption {
+	return func(cfg *consumerConfig) {
+		cfg.backoff = initial
+		cfg.maxBackoff = max
+	}
+}
+
+// NewChangeStreamConsumer returns a consumer decoding the events of w into T.
+func NewChangeStreamConsumer[T any](w Watcher, opts ...ConsumerOption) ChangeStreamConsumer[T] {
+	cfg := consumerConfig{
+		backoff:    100 * time.Millisecond,
+		maxBackoff: 30 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return &consumer[T]{
+		watcher: w,
+		cfg:     cfg,
+	}
+}
+
+type consumer[T any] struct {
+	watcher Watcher
+	cfg     consumerConfig
+}
+
+func (c *consumer[T]) Run(ctx context.Context, handler func(ctx context.Context, event ChangeEvent[T]) error) error {
+	var token bson.Raw
+	if c.cfg.store != nil {
+		var err error
+		if token, err = c.cfg.store.Load(ctx, c.cfg.key); err != nil {
+			return err
+		}
+	}
+
+	wait := c.cfg.backoff
+	for {
+		handled, err := c.consume(ctx, &token, handler)
+		if handled {
+			wait = c.cfg.backoff
+		}
+		if err == nil || !isResumable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		if wait *= 2; wait > c.cfg.maxBackoff {
+			wait = c.cfg.maxBackoff
+		}
+	}
+}
+
+// consume opens a stream after token and handles events until the stream
+// ends, advancing token as it goes. It reports whether any event was
+// handled.
+func (c *consumer[T]) consume(ctx context.Context, token *bson.Raw, handler func(ctx context.Context, event ChangeEvent[T]) error) (handled bool, err error) {
+	opts := options.MergeChangeStreamOptions(c.cfg.streamOpts...)
+	if *token != nil {
+		opts.SetResumeAfter(*token)
+		opts.StartAfter = nil
+		opts.StartAtOperationTime = nil
+	}
+
+	cs, err := c.watcher.Watch(ctx, c.cfg.pipeline, opts)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		err = appendErr(err, cs.Close(context.Background()))
+	}()
+
+	for cs.Next(ctx) {
+		var event ChangeEvent[T]
+		if err := cs.Decode(&event); err != nil {
+			return handled, err
+		}
+		if err := handler(ctx, event); err != nil {
+			return handled, err
+		}
+		handled = true
+
+		*token = cs.ResumeToken()
+		if c.cfg.store != nil {
+			if err := c.cfg.store.Save(ctx, c.cfg.key, *token); err != nil {
+				return handled, err
+			}
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return handled, err
+	}
+	return handled, cs.Err()
+}
+
+// resumableCodes are the server error codes after which a change stream can
+// be reopened from its last resume token.
+var resumableCodes = []int{
+	6,     // HostUnreachable
+	7,     // HostNotFound
+	43,    // CursorNotFound
+	63,    // StaleShardVersion
+	89,    // NetworkTimeout
+	91,    // ShutdownInProgress
+	133,   // FailedToSatisfyReadPreference
+	150,   // StaleEpoch
+	189,   // PrimarySteppedDown
+	234,   // RetryChangeStream
+	262,   // ExceededTimeLimit
+	9001,  // SocketException
+	10107, // NotWritablePrimary
+	11600, // InterruptedAtShutdown
+	11602, // InterruptedDueToReplStateChange
+	13388, // StaleConfig
+	13435, // NotPrimaryNoSecondaryOk
+	13436, // NotPrimaryOrSecondary
+}
+
+func isResumable(err error) bool {
+	if mongo.IsNetworkError(err) || hasErrorLabel(err, "ResumableChangeStreamError") {
+		return true
+	}
+
+	var se mongo.ServerError
+	if !errors.As(err, &se) {
+		return false
+	}
+	for _, code := range resumableCodes {
+		if se.HasErrorCode(code) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewCollectionTokenStore returns a ResumeTokenStore keeping one document
+// per key in coll.
+func NewCollectionTokenStore(coll Collection) ResumeTokenStore {
+	return &collectionTokenStore{
+		coll: coll,
+	}
+}
+
+type collectionTokenStore struct {
+	coll Collection
+}
+
+type tokenDocument struct {
+	Key       string    `bson:"_id"`
+	Token     bson.Raw  `bson:"token"`
+	UpdatedAt time.Time `bson:"updatedAt"`
+}
+
+func (s *collectionTokenStore) Load(ctx context.Context, key string) (bson.Raw, error) {
+	var doc tokenDocument
+	err := s.coll.FindOne(ctx, bson.D{{Key: "_id", Value: key}}, &doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return doc.Token, nil
+}
+
+func (s *collectionTokenStore) Save(ctx context.Context, key string, token bson.Raw) error {
+	doc := tokenDocument{Key: key, Token: token, UpdatedAt: time.Now().UTC()}
+	_, err := s.coll.ReplaceOne(ctx, bson.D{{Key: "_id", Value: key}}, doc, options.Replace().SetUpsert(true))
+	return err
+}
@@ -0,0 +1,123 @@
+package mongodb_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/subratohld/mongodb"
+	"github.com/subratohld/mongodb/mongodbtest"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// testWatcher signals every Watch call and makes the first stream fail with
+// a resumable error after one event.
+type testWatcher struct {
+	mongodb.Watcher
+	opened chan struct{}
+	fail   bool
+}
+
+func (w *testWatcher) Watch(ctx context.Context, pipeline interface{}, opts ...*options.ChangeStreamOptions) (mongodb.ChangeStream, error) {
+	cs, err := w.Watcher.Watch(ctx, pipeline, opts...)
+	if err == nil && w.fail {
+		w.fail = false
+		cs = &failingStream{ChangeStream: cs, left: 1}
+	}
+	w.opened <- struct{}{}
+	return cs, err
+}
+
+type failingStream struct {
+	mongodb.ChangeStream
+	left int
+	err  error
+}
+
+func (s *failingStream) Next(ctx context.Context) bool {
+	if s.left == 0 {
+		s.err = mongo.CommandError{Code: 234, Name: "RetryChangeStream"}
+		return false
+	}
+	s.left--
+	return s.ChangeStream.Next(ctx)
+}
+
+func (s *failingStream) Err() error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.ChangeStream.Err()
+}
+
+func TestChangeStreamConsumer(t *testing.T) {
+	db := mongodbtest.NewClient().Database("testdb")
+	users := db.Collection("users")
+	store := mongodb.NewCollectionTokenStore(db.Collection("resume_tokens"))
+
+	w := &testWatcher{Watcher: users, opened: make(chan struct{}, 2), fail: true}
+	consumer := mongodb.NewChangeStreamConsumer[user](w,
+		mongodb.WithResumeTokenStore(store, "users"),
+		mongodb.WithRestartBackoff(time.Millisecond, time.Millisecond),
+		mongodb.WithConsumerStreamOptions(options.ChangeStream().SetFullDocument(options.UpdateLookup)),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var events []mongodb.ChangeEvent[user]
+	done := make(chan error)
+	go func() {
+		done <- consumer.Run(ctx, func(ctx context.Context, event mongodb.ChangeEvent[user]) error {
+			events = append(events, event)
+			if len(events) == 3 {
+				cancel()
+			}
+			return nil
+		})
+	}()
+
+	<-w.opened
+	if _, err := users.InsertMany(ctx, []interface{}{user{Id: "u1", Name: "Priya", Age: 22}, user{Id: "u2", Name: "Shekhar", Age: 25}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := users.UpdateByID(ctx, "u1", bson.M{"$set": bson.M{"age": 23}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run = %v, want context.Canceled", err)
+	}
+	if len(w.opened) != 1 {
+		t.Errorf("stream was not reopened after a resumable error")
+	}
+	if len(events) != 3 || events[0].FullDocument.Name != "Priya" || events[1].FullDocument.Name != "Shekhar" {
+		t.Fatalf("events = %+v", events)
+	}
+	if ev := events[2]; ev.OperationType != "update" || ev.FullDocument.Age != 23 || ev.UpdateDescription == nil {
+		t.Errorf("update event = %+v", ev)
+	}
+
+	// A new consumer resumes after the last handled event.
+	if _, err := users.DeleteOne(context.TODO(), bson.M{"_id": "u2"}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var next mongodb.ChangeEvent[user]
+	err := mongodb.NewChangeStreamConsumer[user](users, mongodb.WithResumeTokenStore(store, "users")).
+		Run(ctx, func(ctx context.Context, event mongodb.ChangeEvent[user]) error {
+			next = event
+			return errors.New("stop")
+		})
+	if err == nil || err.Error() != "stop" {
+		t.Fatalf("Run = %v, want the handler error", err)
+	}
+	if next.OperationType != "delete" || next.FullDocument != nil {
+		t.Errorf("resumed event = %+v", next)
+	}
+}
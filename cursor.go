@@ -0,0 +1,83 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// MultiError holds every error raised by a single operation, most important
+// first. Reading a cursor and then closing it can both fail, and neither
+// error may hide the other.
+type MultiError []error
+
+func (me MultiError) Error() string {
+	msgs := make([]string, len(me))
+	for i, err := range me {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Is reports whether any contained error matches target. errors.Is only
+// follows an Unwrap returning []error from Go 1.20 on.
+func (me MultiError) Is(target error) bool {
+	for _, err := range me {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first contained error that matches target.
+func (me MultiError) As(target interface{}) bool {
+	for _, err := range me {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unwrap returns the contained errors.
+func (me MultiError) Unwrap() []error {
+	return me
+}
+
+// appendErr combines errors, keeping a lone error as is.
+func appendErr(err error, errs ...error) error {
+	var all MultiError
+	if me, ok := err.(MultiError); ok {
+		all = append(all, me...)
+	} else if err != nil {
+		all = append(all, err)
+	}
+
+	for _, e := range errs {
+		if e != nil {
+			all = append(all, e)
+		}
+	}
+
+	switch len(all) {
+	case 0:
+		return nil
+	case 1:
+		return all[0]
+	}
+	return all
+}
+
+// cursor is the part of *mongo.Cursor used to drain a result set.
+type cursor interface {
+	All(ctx context.Context, results interface{}) error
+	Close(ctx context.Context) error
+}
+
+// readAll decodes every document of cur into results and always closes cur.
+// A decode error is reported ahead of a close error.
+func readAll(ctx context.Context, cur cursor, results interface{}) error {
+	err := cur.All(ctx, results)
+	return appendErr(err, cur.Close(ctx))
+}
@@ -0,0 +1,80 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type fakeCursor struct {
+	allErr   error
+	closeErr error
+	closed   bool
+}
+
+func (c *fakeCursor) All(ctx context.Context, results interface{}) error {
+	return c.allErr
+}
+
+func (c *fakeCursor) Close(ctx context.Context) error {
+	c.closed = true
+	return c.closeErr
+}
+
+func TestReadAll(t *testing.T) {
+	errDecode := errors.New("decode failed")
+	errClose := errors.New("close failed")
+
+	tests := []struct {
+		name     string
+		allErr   error
+		closeErr error
+		want     []error
+	}{
+		{"success", nil, nil, nil},
+		{"decode error survives successful close", errDecode, nil, []error{errDecode}},
+		{"close error", nil, errClose, []error{errClose}},
+		{"both errors", errDecode, errClose, []error{errDecode, errClose}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cur := &fakeCursor{allErr: tt.allErr, closeErr: tt.closeErr}
+			err := readAll(context.TODO(), cur, nil)
+
+			if !cur.closed {
+				t.Error("cursor was not closed")
+			}
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				return
+			}
+			for _, want := range tt.want {
+				if !errors.Is(err, want) {
+					t.Errorf("err = %v, want it to wrap %v", err, want)
+				}
+			}
+
+			var me MultiError
+			if isMulti := errors.As(err, &me); isMulti != (len(tt.want) > 1) {
+				t.Fatalf("err = %#v, MultiError = %v", err, isMulti)
+			}
+			if len(tt.want) > 1 && me[0] != errDecode {
+				t.Errorf("first error = %v, want the decode error", me[0])
+			}
+		})
+	}
+}
+
+func TestMultiErrorAs(t *testing.T) {
+	err := appendErr(errors.New("decode failed"), mongo.CommandError{Code: 43, Name: "CursorNotFound"})
+
+	var cmdErr mongo.CommandError
+	if !errors.As(err, &cmdErr) || cmdErr.Code != 43 {
+		t.Fatalf("errors.As(%v) = %+v", err, cmdErr)
+	}
+}
@@ -10,10 +10,13 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/writeconcern"
 )
 
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
 type Database interface {
 	Name() string
 	Client() Client
 	Collection(name string, opts ...*options.CollectionOptions) Collection
+	CollectionWith(name string, opts ...CollectionOption) Collection
 	CreateCollection(context.Context, string, ...*options.CreateCollectionOptions) error
 	ListCollections(ctx context.Context, filter interface{}, opts ...*options.ListCollectionsOptions) (*mongo.Cursor, error)
 	ListCollectionNames(ctx context.Context, filter interface{}, opts ...*options.ListCollectionsOptions) ([]string, error)
@@ -26,20 +29,33 @@ type Database interface {
 	ReadConcern() *readconcern.ReadConcern
 	WriteConcern() *writeconcern.WriteConcern
 	ReadPreference() *readpref.ReadPref
-	Watch(ctx context.Context, pipeline interface{}, opts ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error)
+	Watch(ctx context.Context, pipeline interface{}, opts ...*options.ChangeStreamOptions) (ChangeStream, error)
 }
 
-func newDB(c *client, name string, opts ...*options.DatabaseOptions) Database {
+func newDB(c *client, sess mongo.Session, name string, opts ...*options.DatabaseOptions) Database {
 	db := c.Client.Database(name, opts...)
 	return &database{
 		Database: db,
 		client:   c,
+		sess:     sess,
 	}
 }
 
 type database struct {
 	*mongo.Database
 	client *client
+	// sess is set for handles obtained from a TxContext.
+	sess mongo.Session
+}
+
+// bind attaches the session of a transaction-bound handle to ctx, unless ctx
+// already carries a session. Listing, dropping, estimated counts and change
+// streams are left unbound, as transactions do not allow them.
+func (db *database) bind(ctx context.Context) context.Context {
+	if db.sess == nil || mongo.SessionFromContext(ctx) != nil {
+		return ctx
+	}
+	return mongo.NewSessionContext(ctx, db.sess)
 }
 
 func (db *database) Client() Client {
@@ -47,5 +63,37 @@ func (db *database) Client() Client {
 }
 
 func (db *database) Collection(name string, opts ...*options.CollectionOptions) Collection {
-	return newCollection(db, name, opts...)
+	return db.CollectionWith(name, WithCollectionOptions(opts...))
+}
+
+func (db *database) CollectionWith(name string, opts ...CollectionOption) Collection {
+	cfg := newCollectionConfig(WithCollectionInterceptors(db.client.interceptors...))
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg.wrap(newCollection(db, name, cfg.driverOpts...))
+}
+
+func (db *database) CreateCollection(ctx context.Context, name string, opts ...*options.CreateCollectionOptions) error {
+	return db.Database.CreateCollection(db.bind(ctx), name, opts...)
+}
+
+func (db *database) CreateView(ctx context.Context, name, viewOn string, pipeline interface{}, opts ...*options.CreateViewOptions) error {
+	return db.Database.CreateView(db.bind(ctx), name, viewOn, pipeline, opts...)
+}
+
+func (db *database) Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (*mongo.Cursor, error) {
+	return db.Database.Aggregate(db.bind(ctx), pipeline, opts...)
+}
+
+func (db *database) RunCommand(ctx context.Context, runCmd interface{}, opts ...*options.RunCmdOptions) *mongo.SingleResult {
+	return db.Database.RunCommand(db.bind(ctx), runCmd, opts...)
+}
+
+func (db *database) RunCommandCursor(ctx context.Context, runCmd interface{}, opts ...*options.RunCmdOptions) (*mongo.Cursor, error) {
+	return db.Database.RunCommandCursor(db.bind(ctx), runCmd, opts...)
+}
+
+func (db *database) Watch(ctx context.Context, pipeline interface{}, opts ...*options.ChangeStreamOptions) (ChangeStream, error) {
+	return changeStream(db.Database.Watch(ctx, pipeline, opts...))
 }
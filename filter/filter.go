@@ -0,0 +1,202 @@
+// Package filter builds query filters for the mongodb package.
+//
+//	f := filter.Eq("status", "active").
+//		And(filter.Gte("age", 18)).
+//		Or(filter.In("role", "admin", "owner"))
+//	err := coll.Find(ctx, f, &users)
+//
+// A Filter marshals to BSON, so it can be passed anywhere a filter is
+// accepted, and it can be nested inside hand-written bson.D documents. The
+// zero Filter matches every document.
+package filter
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+type Filter struct {
+	d bson.D
+}
+
+func field(name, op string, value interface{}) Filter {
+	return Filter{d: bson.D{{Key: name, Value: bson.D{{Key: op, Value: value}}}}}
+}
+
+// logical combines filters with op. A single filter is returned as is,
+// except under $nor, and no filters give the zero Filter.
+func logical(op string, filters []Filter) Filter {
+	if len(filters) == 0 {
+		return Filter{}
+	}
+	if len(filters) == 1 && op != opNor {
+		return filters[0]
+	}
+
+	conds := make(bson.A, 0, len(filters))
+	for _, f := range filters {
+		// Flatten nested use of the same operator, so that
+		// a.And(b).And(c) renders as a single $and.
+		if nested, ok := f.operands(op); ok && op != opNor {
+			conds = append(conds, nested...)
+			continue
+		}
+		conds = append(conds, f.D())
+	}
+	return Filter{d: bson.D{{Key: op, Value: conds}}}
+}
+
+// operands returns the operands of f if f consists of op alone.
+func (f Filter) operands(op string) (bson.A, bool) {
+	if len(f.d) != 1 || f.d[0].Key != op {
+		return nil, false
+	}
+	a, ok := f.d[0].Value.(bson.A)
+	return a, ok
+}
+
+// D returns the filter document.
+func (f Filter) D() bson.D {
+	if f.d == nil {
+		return bson.D{}
+	}
+	return append(bson.D(nil), f.d...)
+}
+
+func (f Filter) MarshalBSON() ([]byte, error) {
+	return bson.Marshal(f.D())
+}
+
+// IsZero reports whether the filter matches every document.
+func (f Filter) IsZero() bool {
+	return len(f.d) == 0
+}
+
+// And returns a filter matching documents that match f and all of others.
+func (f Filter) And(others ...Filter) Filter {
+	return And(append([]Filter{f}, others...)...)
+}
+
+// Or returns a filter matching documents that match f or any of others.
+func (f Filter) Or(others ...Filter) Filter {
+	return Or(append([]Filter{f}, others...)...)
+}
+
+// And matches documents that match every filter. Zero filters are ignored.
+func And(filters ...Filter) Filter {
+	return logical(opAnd, nonZero(filters))
+}
+
+// Or matches documents that match at least one filter. A zero filter
+// matches every document, and so does an Or containing one.
+func Or(filters ...Filter) Filter {
+	if len(nonZero(filters)) < len(filters) {
+		return Filter{}
+	}
+	return logical(opOr, filters)
+}
+
+// Nor matches documents that match none of the filters. Zero filters are
+// ignored.
+func Nor(filters ...Filter) Filter {
+	return logical(opNor, nonZero(filters))
+}
+
+// Not matches documents that do not match f.
+func Not(f Filter) Filter {
+	return Nor(f)
+}
+
+func nonZero(filters []Filter) []Filter {
+	out := make([]Filter, 0, len(filters))
+	for _, f := range filters {
+		if !f.IsZero() {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func Eq(name string, value interface{}) Filter {
+	return Filter{d: bson.D{{Key: name, Value: value}}}
+}
+
+func Ne(name string, value interface{}) Filter {
+	return field(name, "$ne", value)
+}
+
+func Gt(name string, value interface{}) Filter {
+	return field(name, "$gt", value)
+}
+
+func Gte(name string, value interface{}) Filter {
+	return field(name, "$gte", value)
+}
+
+func Lt(name string, value interface{}) Filter {
+	return field(name, "$lt", value)
+}
+
+func Lte(name string, value interface{}) Filter {
+	return field(name, "$lte", value)
+}
+
+func In(name string, values ...interface{}) Filter {
+	return field(name, "$in", bson.A(values))
+}
+
+func Nin(name string, values ...interface{}) Filter {
+	return field(name, "$nin", bson.A(values))
+}
+
+func Exists(name string, exists bool) Filter {
+	return field(name, "$exists", exists)
+}
+
+func Type(name string, t bsontype.Type) Filter {
+	return field(name, "$type", int32(t))
+}
+
+// Regex matches string fields against pattern, using the $regex option
+// letters in options, e.g. "i" for case-insensitive matching.
+func Regex(name, pattern, options string) Filter {
+	d := bson.D{{Key: "$regex", Value: pattern}}
+	if options != "" {
+		d = append(d, bson.E{Key: "$options", Value: options})
+	}
+	return Filter{d: bson.D{{Key: name, Value: d}}}
+}
+
+func Mod(name string, divisor, remainder int64) Filter {
+	return field(name, "$mod", bson.A{divisor, remainder})
+}
+
+// Expr matches documents for which the aggregation expression expr is true.
+func Expr(expr interface{}) Filter {
+	return Filter{d: bson.D{{Key: "$expr", Value: expr}}}
+}
+
+// Where matches documents for which the JavaScript function is true.
+func Where(function string) Filter {
+	return Filter{d: bson.D{{Key: "$where", Value: function}}}
+}
+
+func All(name string, values ...interface{}) Filter {
+	return field(name, "$all", bson.A(values))
+}
+
+func Size(name string, size int) Filter {
+	return field(name, "$size", size)
+}
+
+// ElemMatch matches arrays with at least one element matching f. Field names
+// in f are relative to the array element.
+func ElemMatch(name string, f Filter) Filter {
+	return field(name, "$elemMatch", f.D())
+}
+
+const (
+	opAnd = "$and"
+	opOr  = "$or"
+	opNor = "$nor"
+)
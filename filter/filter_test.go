@@ -0,0 +1,81 @@
+package filter_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/subratohld/mongodb/filter"
+	"github.com/subratohld/mongodb/mongodbtest"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		f    filter.Filter
+		want bson.D
+	}{
+		{"zero", filter.Filter{}, bson.D{}},
+		{"eq", filter.Eq("name", "Priya"), bson.D{{Key: "name", Value: "Priya"}}},
+		{"in", filter.In("role", "admin", "owner"), bson.D{{Key: "role", Value: bson.D{{Key: "$in", Value: bson.A{"admin", "owner"}}}}}},
+		{"and flattens", filter.Eq("a", 1).And(filter.Gt("b", 2)).And(filter.Lt("c", 3)), bson.D{{Key: "$and", Value: bson.A{
+			bson.D{{Key: "a", Value: 1}},
+			bson.D{{Key: "b", Value: bson.D{{Key: "$gt", Value: 2}}}},
+			bson.D{{Key: "c", Value: bson.D{{Key: "$lt", Value: 3}}}},
+		}}}},
+		{"and ignores zero", filter.And(filter.Filter{}, filter.Eq("a", 1)), bson.D{{Key: "a", Value: 1}}},
+		{"or of and", filter.Eq("a", 1).And(filter.Eq("b", 2)).Or(filter.Eq("c", 3)), bson.D{{Key: "$or", Value: bson.A{
+			bson.D{{Key: "$and", Value: bson.A{bson.D{{Key: "a", Value: 1}}, bson.D{{Key: "b", Value: 2}}}}},
+			bson.D{{Key: "c", Value: 3}},
+		}}}},
+		{"or with zero", filter.Or(filter.Eq("a", 1), filter.Filter{}), bson.D{}},
+		{"not", filter.Not(filter.Eq("a", 1)), bson.D{{Key: "$nor", Value: bson.A{bson.D{{Key: "a", Value: 1}}}}}},
+		{"regex", filter.Regex("name", "^pri", "i"), bson.D{{Key: "name", Value: bson.D{{Key: "$regex", Value: "^pri"}, {Key: "$options", Value: "i"}}}}},
+		{"type", filter.Type("age", bsontype.Int32), bson.D{{Key: "age", Value: bson.D{{Key: "$type", Value: int32(16)}}}}},
+		{"elemMatch", filter.ElemMatch("scores", filter.Gte("value", 80)), bson.D{{Key: "scores", Value: bson.D{{Key: "$elemMatch", Value: bson.D{{Key: "value", Value: bson.D{{Key: "$gte", Value: 80}}}}}}}}},
+		{"text", filter.Text("coffee", filter.WithLanguage("en"), filter.CaseSensitive()), bson.D{{Key: "$text", Value: bson.D{{Key: "$search", Value: "coffee"}, {Key: "$language", Value: "en"}, {Key: "$caseSensitive", Value: true}}}}},
+		{"near", filter.Near("loc", filter.Point(1, 2), 0, 500), bson.D{{Key: "loc", Value: bson.D{{Key: "$near", Value: bson.D{
+			{Key: "$geometry", Value: filter.Geometry{Type: "Point", Coordinates: bson.A{1.0, 2.0}}},
+			{Key: "$maxDistance", Value: 500.0},
+		}}}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.f.D(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("D() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	ctx := context.TODO()
+	coll := mongodbtest.NewClient().Database("testdb").Collection("users")
+	_, err := coll.InsertMany(ctx, []interface{}{
+		bson.M{"name": "Priya", "age": 22, "role": "dev"},
+		bson.M{"name": "Shekhar", "age": 25, "role": "admin"},
+		bson.M{"name": "Subrato", "age": 31, "role": "owner", "tags": bson.A{"ops"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := filter.Gte("age", 23).And(filter.In("role", "admin", "owner")).Or(filter.Regex("name", "^pri", "i"))
+	var users []struct {
+		Name string `bson:"name"`
+	}
+	if err := coll.Find(ctx, f, &users); err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if len(users) != 3 {
+		t.Errorf("Find = %+v", users)
+	}
+
+	n, err := coll.CountDocuments(ctx, filter.Exists("tags", false))
+	if err != nil || n != 2 {
+		t.Errorf("CountDocuments = %d, %v", n, err)
+	}
+}
@@ -0,0 +1,66 @@
+package filter
+
+import "go.mongodb.org/mongo-driver/bson"
+
+// Geometry is a GeoJSON object.
+type Geometry struct {
+	Type        string      `bson:"type"`
+	Coordinates interface{} `bson:"coordinates"`
+}
+
+// Point returns a GeoJSON point. Longitude comes first, as in GeoJSON.
+func Point(lng, lat float64) Geometry {
+	return Geometry{Type: "Point", Coordinates: bson.A{lng, lat}}
+}
+
+// Polygon returns a GeoJSON polygon. Each ring is a closed list of
+// [longitude, latitude] positions; the first ring is the exterior.
+func Polygon(rings ...[][2]float64) Geometry {
+	coords := make(bson.A, len(rings))
+	for i, ring := range rings {
+		positions := make(bson.A, len(ring))
+		for j, p := range ring {
+			positions[j] = bson.A{p[0], p[1]}
+		}
+		coords[i] = positions
+	}
+	return Geometry{Type: "Polygon", Coordinates: coords}
+}
+
+// GeoWithin matches geometries entirely within g.
+func GeoWithin(name string, g Geometry) Filter {
+	return field(name, "$geoWithin", bson.D{{Key: "$geometry", Value: g}})
+}
+
+// GeoWithinCenterSphere matches geometries within radius radians of the
+// given point on a sphere.
+func GeoWithinCenterSphere(name string, lng, lat, radius float64) Filter {
+	return field(name, "$geoWithin", bson.D{{Key: "$centerSphere", Value: bson.A{bson.A{lng, lat}, radius}}})
+}
+
+// GeoIntersects matches geometries that intersect g.
+func GeoIntersects(name string, g Geometry) Filter {
+	return field(name, "$geoIntersects", bson.D{{Key: "$geometry", Value: g}})
+}
+
+// Near sorts documents by distance from point, nearest first. Distances are
+// in meters; a zero distance leaves the bound unset.
+func Near(name string, point Geometry, minDistance, maxDistance float64) Filter {
+	return field(name, "$near", near(point, minDistance, maxDistance))
+}
+
+// NearSphere is Near with distances calculated on a sphere.
+func NearSphere(name string, point Geometry, minDistance, maxDistance float64) Filter {
+	return field(name, "$nearSphere", near(point, minDistance, maxDistance))
+}
+
+func near(point Geometry, minDistance, maxDistance float64) bson.D {
+	d := bson.D{{Key: "$geometry", Value: point}}
+	if minDistance > 0 {
+		d = append(d, bson.E{Key: "$minDistance", Value: minDistance})
+	}
+	if maxDistance > 0 {
+		d = append(d, bson.E{Key: "$maxDistance", Value: maxDistance})
+	}
+	return d
+}
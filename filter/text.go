@@ -0,0 +1,33 @@
+package filter
+
+import "go.mongodb.org/mongo-driver/bson"
+
+// TextOption configures a Text filter.
+type TextOption func(*bson.D)
+
+func WithLanguage(language string) TextOption {
+	return func(d *bson.D) {
+		*d = append(*d, bson.E{Key: "$language", Value: language})
+	}
+}
+
+func CaseSensitive() TextOption {
+	return func(d *bson.D) {
+		*d = append(*d, bson.E{Key: "$caseSensitive", Value: true})
+	}
+}
+
+func DiacriticSensitive() TextOption {
+	return func(d *bson.D) {
+		*d = append(*d, bson.E{Key: "$diacriticSensitive", Value: true})
+	}
+}
+
+// Text performs a text search on the collection's text index.
+func Text(search string, opts ...TextOption) Filter {
+	d := bson.D{{Key: "$search", Value: search}}
+	for _, opt := range opts {
+		opt(&d)
+	}
+	return Filter{d: bson.D{{Key: "$text", Value: d}}}
+}
@@ -0,0 +1,60 @@
+package mongodb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+type IndexView interface {
+	List(ctx context.Context, opts ...*options.ListIndexesOptions) (Iterator, error)
+	ListSpecifications(ctx context.Context, opts ...*options.ListIndexesOptions) ([]*mongo.IndexSpecification, error)
+	CreateOne(ctx context.Context, model mongo.IndexModel, opts ...*options.CreateIndexesOptions) (string, error)
+	CreateMany(ctx context.Context, models []mongo.IndexModel, opts ...*options.CreateIndexesOptions) ([]string, error)
+	DropOne(ctx context.Context, name string, opts ...*options.DropIndexesOptions) (bson.Raw, error)
+	DropAll(ctx context.Context, opts ...*options.DropIndexesOptions) (bson.Raw, error)
+}
+
+func newIndexView(coll *collection) IndexView {
+	return &indexView{
+		IndexView: coll.Collection.Indexes(),
+		coll:      coll,
+	}
+}
+
+type indexView struct {
+	mongo.IndexView
+	coll *collection
+}
+
+func (iv *indexView) List(ctx context.Context, opts ...*options.ListIndexesOptions) (Iterator, error) {
+	cur, err := iv.IndexView.List(iv.coll.bind(ctx), opts...)
+	if err != nil {
+		return nil, err
+	}
+	return cur, nil
+}
+
+func (iv *indexView) ListSpecifications(ctx context.Context, opts ...*options.ListIndexesOptions) ([]*mongo.IndexSpecification, error) {
+	return iv.IndexView.ListSpecifications(iv.coll.bind(ctx), opts...)
+}
+
+func (iv *indexView) CreateOne(ctx context.Context, model mongo.IndexModel, opts ...*options.CreateIndexesOptions) (string, error) {
+	return iv.IndexView.CreateOne(iv.coll.bind(ctx), model, opts...)
+}
+
+func (iv *indexView) CreateMany(ctx context.Context, models []mongo.IndexModel, opts ...*options.CreateIndexesOptions) ([]string, error) {
+	return iv.IndexView.CreateMany(iv.coll.bind(ctx), models, opts...)
+}
+
+func (iv *indexView) DropOne(ctx context.Context, name string, opts ...*options.DropIndexesOptions) (bson.Raw, error) {
+	return iv.IndexView.DropOne(iv.coll.bind(ctx), name, opts...)
+}
+
+func (iv *indexView) DropAll(ctx context.Context, opts ...*options.DropIndexesOptions) (bson.Raw, error) {
+	return iv.IndexView.DropAll(iv.coll.bind(ctx), opts...)
+}
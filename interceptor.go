@@ -0,0 +1,336 @@
+package mongodb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// OperationInfo describes a Collection operation to interceptors.
+type OperationInfo struct {
+	// Name is the Collection method, e.g. "FindOne" or "UpdateMany", or
+	// the IndexView method prefixed with "Indexes.", e.g.
+	// "Indexes.CreateOne".
+	Name       string
+	Database   string
+	Collection string
+	// Filter is the query filter. UpdateByID reports {_id: id}.
+	Filter interface{}
+	// Update is the update document or pipeline, or the replacement.
+	Update interface{}
+	// Pipeline is the aggregation or change stream pipeline.
+	Pipeline interface{}
+	// Documents holds the documents passed to InsertOne and InsertMany.
+	Documents []interface{}
+	// Models holds the write models passed to BulkWrite.
+	Models []mongo.WriteModel
+	// IndexModels holds the models passed to Indexes().CreateOne and
+	// CreateMany.
+	IndexModels []mongo.IndexModel
+	// IndexName is the index passed to Indexes().DropOne.
+	IndexName string
+	// Result points at what the operation produces once next returns: the
+	// caller's target for methods that decode into one, otherwise a
+	// variable of the method's result type, such as *int64 for
+	// CountDocuments, **mongo.UpdateResult for UpdateOne or *string for
+	// Indexes.CreateOne. It is nil for FindEach and Drop.
+	Result interface{}
+}
+
+// Namespace returns "database.collection".
+func (op OperationInfo) Namespace() string {
+	return op.Database + "." + op.Collection
+}
+
+// Invoker runs the rest of an interceptor chain. The filter, update,
+// pipeline, documents and models of op are the ones sent to the server, so
+// an interceptor may rewrite them before calling next.
+type Invoker func(ctx context.Context, op OperationInfo) error
+
+// Interceptor wraps every operation of a Collection. It must call next to
+// run the operation, and may inspect op.Result afterwards.
+type Interceptor func(ctx context.Context, op OperationInfo, next Invoker) error
+
+// chain builds an Invoker running interceptors in order around last.
+func chain(interceptors []Interceptor, last Invoker) Invoker {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		intercept, next := interceptors[i], last
+		last = func(ctx context.Context, op OperationInfo) error {
+			return intercept(ctx, op, next)
+		}
+	}
+	return last
+}
+
+type interceptedCollection struct {
+	Collection
+	interceptors []Interceptor
+}
+
+func (ic *interceptedCollection) invoke(ctx context.Context, op OperationInfo, call Invoker) error {
+	op.Database = ic.Database().Name()
+	op.Collection = ic.Name()
+	return chain(ic.interceptors, call)(ctx, op)
+}
+
+func (ic *interceptedCollection) Clone(opts ...*options.CollectionOptions) (Collection, error) {
+	c, err := ic.Collection.Clone(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &interceptedCollection{Collection: c, interceptors: ic.interceptors}, nil
+}
+
+func (ic *interceptedCollection) Drop(ctx context.Context) error {
+	return ic.invoke(ctx, OperationInfo{Name: "Drop"}, func(ctx context.Context, op OperationInfo) error {
+		return ic.Collection.Drop(ctx)
+	})
+}
+
+func (ic *interceptedCollection) InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (interface{}, error) {
+	var id interface{}
+	err := ic.invoke(ctx, OperationInfo{Name: "InsertOne", Documents: []interface{}{document}, Result: &id}, func(ctx context.Context, op OperationInfo) (err error) {
+		id, err = ic.Collection.InsertOne(ctx, op.Documents[0], opts...)
+		return err
+	})
+	return id, err
+}
+
+func (ic *interceptedCollection) InsertMany(ctx context.Context, documents []interface{}, opts ...*options.InsertManyOptions) ([]interface{}, error) {
+	var ids []interface{}
+	err := ic.invoke(ctx, OperationInfo{Name: "InsertMany", Documents: documents, Result: &ids}, func(ctx context.Context, op OperationInfo) (err error) {
+		ids, err = ic.Collection.InsertMany(ctx, op.Documents, opts...)
+		return err
+	})
+	return ids, err
+}
+
+func (ic *interceptedCollection) UpdateByID(ctx context.Context, id interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	if id == nil {
+		return nil, mongo.ErrNilDocument
+	}
+
+	var res *mongo.UpdateResult
+	op := OperationInfo{Name: "UpdateByID", Filter: bson.D{{Key: "_id", Value: id}}, Update: update, Result: &res}
+	err := ic.invoke(ctx, op, func(ctx context.Context, op OperationInfo) (err error) {
+		res, err = ic.Collection.UpdateOne(ctx, op.Filter, op.Update, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (ic *interceptedCollection) UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	var res *mongo.UpdateResult
+	err := ic.invoke(ctx, OperationInfo{Name: "UpdateOne", Filter: filter, Update: update, Result: &res}, func(ctx context.Context, op OperationInfo) (err error) {
+		res, err = ic.Collection.UpdateOne(ctx, op.Filter, op.Update, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (ic *interceptedCollection) UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	var res *mongo.UpdateResult
+	err := ic.invoke(ctx, OperationInfo{Name: "UpdateMany", Filter: filter, Update: update, Result: &res}, func(ctx context.Context, op OperationInfo) (err error) {
+		res, err = ic.Collection.UpdateMany(ctx, op.Filter, op.Update, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (ic *interceptedCollection) DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	var res *mongo.DeleteResult
+	err := ic.invoke(ctx, OperationInfo{Name: "DeleteOne", Filter: filter, Result: &res}, func(ctx context.Context, op OperationInfo) (err error) {
+		res, err = ic.Collection.DeleteOne(ctx, op.Filter, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (ic *interceptedCollection) DeleteMany(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	var res *mongo.DeleteResult
+	err := ic.invoke(ctx, OperationInfo{Name: "DeleteMany", Filter: filter, Result: &res}, func(ctx context.Context, op OperationInfo) (err error) {
+		res, err = ic.Collection.DeleteMany(ctx, op.Filter, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (ic *interceptedCollection) FindOne(ctx context.Context, filter interface{}, result interface{}, opts ...*options.FindOneOptions) error {
+	return ic.invoke(ctx, OperationInfo{Name: "FindOne", Filter: filter, Result: result}, func(ctx context.Context, op OperationInfo) error {
+		return ic.Collection.FindOne(ctx, op.Filter, result, opts...)
+	})
+}
+
+func (ic *interceptedCollection) Find(ctx context.Context, filter interface{}, results interface{}, opts ...*options.FindOptions) error {
+	return ic.invoke(ctx, OperationInfo{Name: "Find", Filter: filter, Result: results}, func(ctx context.Context, op OperationInfo) error {
+		return ic.Collection.Find(ctx, op.Filter, results, opts...)
+	})
+}
+
+func (ic *interceptedCollection) FindIterator(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (Iterator, error) {
+	var it Iterator
+	err := ic.invoke(ctx, OperationInfo{Name: "FindIterator", Filter: filter, Result: &it}, func(ctx context.Context, op OperationInfo) (err error) {
+		it, err = ic.Collection.FindIterator(ctx, op.Filter, opts...)
+		return err
+	})
+	return it, err
+}
+
+func (ic *interceptedCollection) FindEach(ctx context.Context, filter interface{}, fn func(decode func(v interface{}) error) error, opts ...*options.FindOptions) error {
+	return ic.invoke(ctx, OperationInfo{Name: "FindEach", Filter: filter}, func(ctx context.Context, op OperationInfo) error {
+		return ic.Collection.FindEach(ctx, op.Filter, fn, opts...)
+	})
+}
+
+func (ic *interceptedCollection) FindOneAndDelete(ctx context.Context, filter interface{}, target interface{}, opts ...*options.FindOneAndDeleteOptions) error {
+	return ic.invoke(ctx, OperationInfo{Name: "FindOneAndDelete", Filter: filter, Result: target}, func(ctx context.Context, op OperationInfo) error {
+		return ic.Collection.FindOneAndDelete(ctx, op.Filter, target, opts...)
+	})
+}
+
+func (ic *interceptedCollection) FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, target interface{}, opts ...*options.FindOneAndUpdateOptions) error {
+	return ic.invoke(ctx, OperationInfo{Name: "FindOneAndUpdate", Filter: filter, Update: update, Result: target}, func(ctx context.Context, op OperationInfo) error {
+		return ic.Collection.FindOneAndUpdate(ctx, op.Filter, op.Update, target, opts...)
+	})
+}
+
+func (ic *interceptedCollection) FindOneAndReplace(ctx context.Context, filter interface{}, replace interface{}, target interface{}, opts ...*options.FindOneAndReplaceOptions) error {
+	return ic.invoke(ctx, OperationInfo{Name: "FindOneAndReplace", Filter: filter, Update: replace, Result: target}, func(ctx context.Context, op OperationInfo) error {
+		return ic.Collection.FindOneAndReplace(ctx, op.Filter, op.Update, target, opts...)
+	})
+}
+
+func (ic *interceptedCollection) ReplaceOne(ctx context.Context, filter interface{}, replacement interface{}, opts ...*options.ReplaceOptions) (*mongo.UpdateResult, error) {
+	var res *mongo.UpdateResult
+	err := ic.invoke(ctx, OperationInfo{Name: "ReplaceOne", Filter: filter, Update: replacement, Result: &res}, func(ctx context.Context, op OperationInfo) (err error) {
+		res, err = ic.Collection.ReplaceOne(ctx, op.Filter, op.Update, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (ic *interceptedCollection) Aggregate(ctx context.Context, pipeline interface{}, target interface{}, opts ...*options.AggregateOptions) error {
+	return ic.invoke(ctx, OperationInfo{Name: "Aggregate", Pipeline: pipeline, Result: target}, func(ctx context.Context, op OperationInfo) error {
+		return ic.Collection.Aggregate(ctx, op.Pipeline, target, opts...)
+	})
+}
+
+func (ic *interceptedCollection) AggregateIterator(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (Iterator, error) {
+	var it Iterator
+	err := ic.invoke(ctx, OperationInfo{Name: "AggregateIterator", Pipeline: pipeline, Result: &it}, func(ctx context.Context, op OperationInfo) (err error) {
+		it, err = ic.Collection.AggregateIterator(ctx, op.Pipeline, opts...)
+		return err
+	})
+	return it, err
+}
+
+func (ic *interceptedCollection) BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	var res *mongo.BulkWriteResult
+	err := ic.invoke(ctx, OperationInfo{Name: "BulkWrite", Models: models, Result: &res}, func(ctx context.Context, op OperationInfo) (err error) {
+		res, err = ic.Collection.BulkWrite(ctx, op.Models, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (ic *interceptedCollection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	var n int64
+	err := ic.invoke(ctx, OperationInfo{Name: "CountDocuments", Filter: filter, Result: &n}, func(ctx context.Context, op OperationInfo) (err error) {
+		n, err = ic.Collection.CountDocuments(ctx, op.Filter, opts...)
+		return err
+	})
+	return n, err
+}
+
+func (ic *interceptedCollection) Distinct(ctx context.Context, fieldName string, filter interface{}, opts ...*options.DistinctOptions) ([]interface{}, error) {
+	var values []interface{}
+	err := ic.invoke(ctx, OperationInfo{Name: "Distinct", Filter: filter, Result: &values}, func(ctx context.Context, op OperationInfo) (err error) {
+		values, err = ic.Collection.Distinct(ctx, fieldName, op.Filter, opts...)
+		return err
+	})
+	return values, err
+}
+
+func (ic *interceptedCollection) EstimatedDocumentCount(ctx context.Context, opts ...*options.EstimatedDocumentCountOptions) (int64, error) {
+	var n int64
+	err := ic.invoke(ctx, OperationInfo{Name: "EstimatedDocumentCount", Result: &n}, func(ctx context.Context, op OperationInfo) (err error) {
+		n, err = ic.Collection.EstimatedDocumentCount(ctx, opts...)
+		return err
+	})
+	return n, err
+}
+
+func (ic *interceptedCollection) Watch(ctx context.Context, pipeline interface{}, opts ...*options.ChangeStreamOptions) (ChangeStream, error) {
+	var cs ChangeStream
+	err := ic.invoke(ctx, OperationInfo{Name: "Watch", Pipeline: pipeline, Result: &cs}, func(ctx context.Context, op OperationInfo) (err error) {
+		cs, err = ic.Collection.Watch(ctx, op.Pipeline, opts...)
+		return err
+	})
+	return cs, err
+}
+
+func (ic *interceptedCollection) Indexes() IndexView {
+	return &interceptedIndexView{IndexView: ic.Collection.Indexes(), coll: ic}
+}
+
+type interceptedIndexView struct {
+	IndexView
+	coll *interceptedCollection
+}
+
+func (iv *interceptedIndexView) List(ctx context.Context, opts ...*options.ListIndexesOptions) (Iterator, error) {
+	var it Iterator
+	err := iv.coll.invoke(ctx, OperationInfo{Name: "Indexes.List", Result: &it}, func(ctx context.Context, op OperationInfo) (err error) {
+		it, err = iv.IndexView.List(ctx, opts...)
+		return err
+	})
+	return it, err
+}
+
+func (iv *interceptedIndexView) ListSpecifications(ctx context.Context, opts ...*options.ListIndexesOptions) ([]*mongo.IndexSpecification, error) {
+	var specs []*mongo.IndexSpecification
+	err := iv.coll.invoke(ctx, OperationInfo{Name: "Indexes.ListSpecifications", Result: &specs}, func(ctx context.Context, op OperationInfo) (err error) {
+		specs, err = iv.IndexView.ListSpecifications(ctx, opts...)
+		return err
+	})
+	return specs, err
+}
+
+func (iv *interceptedIndexView) CreateOne(ctx context.Context, model mongo.IndexModel, opts ...*options.CreateIndexesOptions) (string, error) {
+	var name string
+	op := OperationInfo{Name: "Indexes.CreateOne", IndexModels: []mongo.IndexModel{model}, Result: &name}
+	err := iv.coll.invoke(ctx, op, func(ctx context.Context, op OperationInfo) (err error) {
+		name, err = iv.IndexView.CreateOne(ctx, op.IndexModels[0], opts...)
+		return err
+	})
+	return name, err
+}
+
+func (iv *interceptedIndexView) CreateMany(ctx context.Context, models []mongo.IndexModel, opts ...*options.CreateIndexesOptions) ([]string, error) {
+	var names []string
+	err := iv.coll.invoke(ctx, OperationInfo{Name: "Indexes.CreateMany", IndexModels: models, Result: &names}, func(ctx context.Context, op OperationInfo) (err error) {
+		names, err = iv.IndexView.CreateMany(ctx, op.IndexModels, opts...)
+		return err
+	})
+	return names, err
+}
+
+func (iv *interceptedIndexView) DropOne(ctx context.Context, name string, opts ...*options.DropIndexesOptions) (bson.Raw, error) {
+	var res bson.Raw
+	err := iv.coll.invoke(ctx, OperationInfo{Name: "Indexes.DropOne", IndexName: name, Result: &res}, func(ctx context.Context, op OperationInfo) (err error) {
+		res, err = iv.IndexView.DropOne(ctx, op.IndexName, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (iv *interceptedIndexView) DropAll(ctx context.Context, opts ...*options.DropIndexesOptions) (bson.Raw, error) {
+	var res bson.Raw
+	err := iv.coll.invoke(ctx, OperationInfo{Name: "Indexes.DropAll", Result: &res}, func(ctx context.Context, op OperationInfo) (err error) {
+		res, err = iv.IndexView.DropAll(ctx, opts...)
+		return err
+	})
+	return res, err
+}
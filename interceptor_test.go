@@ -0,0 +1,146 @@
+package mongodb_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/subratohld/mongodb"
+	"github.com/subratohld/mongodb/mongodbtest"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestInterceptors(t *testing.T) {
+	ctx := context.TODO()
+
+	var calls []string
+	record := func(tag string) mongodb.Interceptor {
+		return func(ctx context.Context, op mongodb.OperationInfo, next mongodb.Invoker) error {
+			calls = append(calls, tag+" "+op.Name+" "+op.Namespace())
+			return next(ctx, op)
+		}
+	}
+	// tenant restricts every filter to the "acme" tenant.
+	tenant := func(ctx context.Context, op mongodb.OperationInfo, next mongodb.Invoker) error {
+		if op.Filter != nil {
+			op.Filter = bson.D{{Key: "$and", Value: bson.A{op.Filter, bson.M{"tenant": "acme"}}}}
+		}
+		return next(ctx, op)
+	}
+	var updated int64
+	results := func(ctx context.Context, op mongodb.OperationInfo, next mongodb.Invoker) error {
+		err := next(ctx, op)
+		if res, ok := op.Result.(**mongo.UpdateResult); ok && err == nil {
+			updated += (*res).ModifiedCount
+		}
+		return err
+	}
+
+	coll := mongodbtest.NewClient().Database("testdb").CollectionWith("users",
+		mongodb.WithCollectionInterceptors(record("outer"), record("inner"), tenant, results))
+
+	if _, err := coll.InsertMany(ctx, []interface{}{
+		bson.M{"_id": "u1", "tenant": "acme"},
+		bson.M{"_id": "u2", "tenant": "other"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := coll.CountDocuments(ctx, bson.M{}); err != nil || n != 1 {
+		t.Fatalf("CountDocuments = %d, %v", n, err)
+	}
+	if _, err := coll.UpdateMany(ctx, bson.M{}, bson.M{"$set": bson.M{"seen": true}}); err != nil {
+		t.Fatal(err)
+	}
+	if updated != 1 {
+		t.Fatalf("updated = %d, want 1", updated)
+	}
+
+	want := []string{
+		"outer InsertMany testdb.users", "inner InsertMany testdb.users",
+		"outer CountDocuments testdb.users", "inner CountDocuments testdb.users",
+		"outer UpdateMany testdb.users", "inner UpdateMany testdb.users",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("calls = %q, want %q", calls, want)
+	}
+}
+
+func TestInterceptorsSeeServerOperations(t *testing.T) {
+	ctx := context.TODO()
+
+	var names []string
+	coll := mongodbtest.NewClient().Database("testdb").CollectionWith("users",
+		mongodb.WithSoftDelete(""),
+		mongodb.WithCollectionInterceptors(func(ctx context.Context, op mongodb.OperationInfo, next mongodb.Invoker) error {
+			names = append(names, op.Name)
+			return next(ctx, op)
+		}))
+
+	if _, err := coll.InsertOne(ctx, bson.M{"_id": "u1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := coll.DeleteOne(ctx, bson.M{"_id": "u1"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"InsertOne", "UpdateOne"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("names = %q, want %q", names, want)
+	}
+}
+
+func TestInterceptorsSeeIndexOperations(t *testing.T) {
+	ctx := context.TODO()
+
+	var names []string
+	coll := mongodbtest.NewClient().Database("testdb").CollectionWith("users",
+		mongodb.WithCollectionInterceptors(func(ctx context.Context, op mongodb.OperationInfo, next mongodb.Invoker) error {
+			names = append(names, op.Name)
+			err := next(ctx, op)
+			if res, ok := op.Result.(*string); ok && err == nil && *res != "email_1" {
+				t.Errorf("CreateOne result = %q", *res)
+			}
+			return err
+		}))
+
+	if _, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "email", Value: 1}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := coll.Indexes().ListSpecifications(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := coll.Indexes().DropOne(ctx, "email_1"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Indexes.CreateOne", "Indexes.ListSpecifications", "Indexes.DropOne"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("names = %q, want %q", names, want)
+	}
+}
+
+func TestInterceptorsRunOnceOnClones(t *testing.T) {
+	ctx := context.TODO()
+
+	var calls int
+	coll := mongodbtest.NewClient().Database("testdb").CollectionWith("users",
+		mongodb.WithTimestamps("createdAt", "updatedAt"),
+		mongodb.WithVersion("version"),
+		mongodb.WithSoftDelete(""),
+		mongodb.WithCollectionInterceptors(func(ctx context.Context, op mongodb.OperationInfo, next mongodb.Invoker) error {
+			calls++
+			return next(ctx, op)
+		}))
+	clone, err := coll.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := clone.InsertOne(ctx, bson.M{"_id": "u1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := clone.DeleteOne(ctx, bson.M{"_id": "u1"}); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Fatalf("interceptor ran %d times for 2 operations", calls)
+	}
+}
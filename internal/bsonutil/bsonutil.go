@@ -0,0 +1,45 @@
+// Package bsonutil holds the document helpers shared by the collection
+// decorators, the in-memory fake and the repository.
+package bsonutil
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ToDoc round-trips v through BSON, so documents, filters and updates are
+// seen exactly the way the driver encodes them. A nil v is
+// mongo.ErrNilDocument.
+func ToDoc(v interface{}) (bson.D, error) {
+	if v == nil {
+		return nil, mongo.ErrNilDocument
+	}
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc bson.D
+	return doc, bson.Unmarshal(data, &doc)
+}
+
+// ToDocs decodes a slice-like value such as mongo.Pipeline or []bson.M.
+func ToDocs(v interface{}) ([]bson.D, error) {
+	data, err := bson.Marshal(bson.D{{Key: "v", Value: v}})
+	if err != nil {
+		return nil, err
+	}
+	var wrapper struct {
+		V []bson.D `bson:"v"`
+	}
+	return wrapper.V, bson.Unmarshal(data, &wrapper)
+}
+
+// Lookup returns the value of the top-level key in doc.
+func Lookup(doc bson.D, key string) (interface{}, bool) {
+	for _, e := range doc {
+		if e.Key == key {
+			return e.Value, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,28 @@
+// Package clock provides the write time used for stamped fields. A caller
+// that needs to know the time a collection stamps can fix it in the context.
+package clock
+
+import (
+	"context"
+	"time"
+)
+
+type nowKey struct{}
+
+// Now returns the current time at the millisecond precision of BSON dates.
+func Now() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+// With returns a context whose writes are stamped with t.
+func With(ctx context.Context, t time.Time) context.Context {
+	return context.WithValue(ctx, nowKey{}, t)
+}
+
+// From returns the time fixed in ctx by With, or Now.
+func From(ctx context.Context) time.Time {
+	if t, ok := ctx.Value(nowKey{}).(time.Time); ok {
+		return t
+	}
+	return Now()
+}
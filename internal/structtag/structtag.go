@@ -0,0 +1,93 @@
+// Package structtag reads the bson and mongodb struct tags shared by the
+// collection options and the repository package.
+package structtag
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Field is a struct field stored in documents.
+type Field struct {
+	reflect.StructField
+	// Name is the document field name.
+	Name string
+	// Index leads to the field from the outer struct, through inlined
+	// structs.
+	Index []int
+	// Tag is the mongodb tag, and Tagged reports whether there is one.
+	Tag    string
+	Tagged bool
+}
+
+// Options returns the comma separated options of the mongodb tag.
+func (f Field) Options() []string {
+	return Options(f.Tag)
+}
+
+// Fields returns the exported fields of struct type t that the default bson
+// codec stores, with the fields of ",inline" structs in place of the struct.
+// Fields tagged bson:"-" and inlined maps are left out.
+func Fields(t reflect.Type) []Field {
+	var fields []Field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name, inline, ok := Name(sf)
+		switch {
+		case !ok:
+		case inline:
+			if sf.Type.Kind() == reflect.Struct {
+				for _, f := range Fields(sf.Type) {
+					f.Index = append([]int{i}, f.Index...)
+					fields = append(fields, f)
+				}
+			}
+		default:
+			tag, tagged := sf.Tag.Lookup("mongodb")
+			fields = append(fields, Field{StructField: sf, Name: name, Index: []int{i}, Tag: tag, Tagged: tagged})
+		}
+	}
+	return fields
+}
+
+// Name returns the document field name of sf following the rules of the
+// default bson codec, whether sf is inlined, and false if sf is not stored.
+func Name(sf reflect.StructField) (name string, inline, ok bool) {
+	tag := sf.Tag.Get("bson")
+	if tag == "-" {
+		return "", false, false
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == "inline" {
+			return "", true, true
+		}
+	}
+	if name == "" {
+		name = strings.ToLower(sf.Name)
+	}
+	return name, false, true
+}
+
+// Options splits a mongodb tag into its trimmed options.
+func Options(tag string) []string {
+	opts := strings.Split(tag, ",")
+	for i, opt := range opts {
+		opts[i] = strings.TrimSpace(opt)
+	}
+	return opts
+}
+
+// Lookup returns the value of the key=value option key in tag.
+func Lookup(tag, key string) (string, bool) {
+	for _, opt := range Options(tag) {
+		if k, v, ok := strings.Cut(opt, "="); ok && k == key {
+			return v, true
+		}
+	}
+	return "", false
+}
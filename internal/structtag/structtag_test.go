@@ -0,0 +1,56 @@
+package structtag
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type audit struct {
+	CreatedAt time.Time `bson:"createdAt" mongodb:"createdAt"`
+	UpdatedAt time.Time `mongodb:"updatedAt"`
+}
+
+type order struct {
+	ID      string                 `bson:"_id" mongodb:"id"`
+	Secret  string                 `bson:"-" mongodb:"version"`
+	Extra   map[string]interface{} `bson:",inline"`
+	Audit   audit                  `bson:",inline"`
+	Status  string                 `bson:"status,omitempty" mongodb:" index , unique "`
+	private string
+}
+
+func TestFields(t *testing.T) {
+	type got struct {
+		Name    string
+		Index   []int
+		Options []string
+	}
+	var fields []got
+	for _, f := range Fields(reflect.TypeOf(order{})) {
+		var opts []string
+		if f.Tagged {
+			opts = f.Options()
+		}
+		fields = append(fields, got{f.Name, f.Index, opts})
+	}
+
+	want := []got{
+		{"_id", []int{0}, []string{"id"}},
+		{"createdAt", []int{3, 0}, []string{"createdAt"}},
+		{"updatedat", []int{3, 1}, []string{"updatedAt"}},
+		{"status", []int{4}, []string{"index", "unique"}},
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("Fields = %+v, want %+v", fields, want)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	if v, ok := Lookup("index, collection=orders", "collection"); !ok || v != "orders" {
+		t.Errorf("Lookup = %q, %v", v, ok)
+	}
+	if _, ok := Lookup("collection", "collection"); ok {
+		t.Error("Lookup found a key without a value")
+	}
+}
@@ -0,0 +1,36 @@
+package mongodb
+
+import (
+	"context"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+// Iterator streams the documents of a result set one at a time, so large
+// results can be processed with bounded memory. *mongo.Cursor satisfies it.
+// The number of documents fetched per round trip is set through the
+// BatchSize field of the find or aggregate options.
+type Iterator interface {
+	Next(ctx context.Context) bool
+	Decode(v interface{}) error
+	Err() error
+	Close(ctx context.Context) error
+	RemainingBatchLength() int
+}
+
+// Each calls fn for every document of it and closes it afterwards. fn
+// receives a decode function for the current document; returning an error
+// from fn stops the iteration and that error is returned.
+func Each(ctx context.Context, it Iterator, fn func(decode func(v interface{}) error) error) error {
+	var err error
+	for it.Next(ctx) {
+		if err = fn(it.Decode); err != nil {
+			break
+		}
+	}
+
+	if err == nil {
+		err = it.Err()
+	}
+	return appendErr(err, it.Close(ctx))
+}
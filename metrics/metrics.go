@@ -0,0 +1,325 @@
+// Package metrics exports Prometheus metrics for MongoDB commands and
+// connection pools, fed by the driver's monitoring events.
+//
+//	m, err := metrics.New(prometheus.DefaultRegisterer)
+//	if err != nil {
+//		return err
+//	}
+//	client, err := mongodb.NewClient(ctx, append(m.Options(), mongodb.WithURI(uri))...)
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/subratohld/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Metrics holds the collectors and the hooks that update them.
+type Metrics interface {
+	// CommandMonitor records the duration and errors of every command.
+	CommandMonitor() *event.CommandMonitor
+	// PoolMonitor tracks checked-out and idle connections.
+	PoolMonitor() *event.PoolMonitor
+	// Interceptor stamps the start of each collection operation so that
+	// CommandMonitor can report how long it took to send its first command.
+	Interceptor() mongodb.Interceptor
+	// Options installs the monitors and the interceptor on a client created
+	// by mongodb.NewClient. They replace any monitors set by earlier options.
+	Options() []mongodb.Option
+}
+
+type Option func(*config)
+
+type config struct {
+	namespace string
+	buckets   []float64
+}
+
+// WithNamespace prefixes metric names with namespace instead of "mongodb".
+func WithNamespace(namespace string) Option {
+	return func(cfg *config) {
+		cfg.namespace = namespace
+	}
+}
+
+// WithBuckets sets the buckets of the command duration and operation wait
+// histograms, in seconds. prometheus.DefBuckets is used by default.
+func WithBuckets(buckets ...float64) Option {
+	return func(cfg *config) {
+		cfg.buckets = buckets
+	}
+}
+
+// New creates the collectors and registers them with reg.
+func New(reg prometheus.Registerer, opts ...Option) (Metrics, error) {
+	cfg := &config{namespace: "mongodb", buckets: prometheus.DefBuckets}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	m := &metrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: cfg.namespace,
+			Name:      "command_duration_seconds",
+			Help:      "Duration of commands by command name and collection.",
+			Buckets:   cfg.buckets,
+		}, []string{"command", "collection"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.namespace,
+			Name:      "command_errors_total",
+			Help:      "Command errors by command name and numeric server error code, or unknown.",
+		}, []string{"command", "code"}),
+		checkedOut: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: cfg.namespace,
+			Name:      "pool_checked_out_connections",
+			Help:      "Connections currently checked out of the pool, by server address.",
+		}, []string{"address"}),
+		idle: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: cfg.namespace,
+			Name:      "pool_idle_connections",
+			Help:      "Open connections waiting in the pool, by server address.",
+		}, []string{"address"}),
+		wait: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: cfg.namespace,
+			Name:      "operation_wait_seconds",
+			Help:      "Time from the start of a collection operation until its first command was sent, by server address.",
+			Buckets:   cfg.buckets,
+		}, []string{"address"}),
+		conns: make(map[string]map[uint64]bool),
+	}
+
+	for _, c := range []prometheus.Collector{m.duration, m.errors, m.checkedOut, m.idle, m.wait} {
+		if err := reg.Register(c); err != nil {
+			return nil, fmt.Errorf("metrics: %w", err)
+		}
+	}
+	return m, nil
+}
+
+type metrics struct {
+	duration   *prometheus.HistogramVec
+	errors     *prometheus.CounterVec
+	checkedOut *prometheus.GaugeVec
+	idle       *prometheus.GaugeVec
+	wait       *prometheus.HistogramVec
+
+	// collections maps in-flight commands to the collection they target,
+	// which only the started event carries.
+	collections sync.Map
+
+	// conns maps the open connections of each pool to whether they are
+	// checked out.
+	mu    sync.Mutex
+	conns map[string]map[uint64]bool
+}
+
+type requestKey struct {
+	conn string
+	id   int64
+}
+
+type startKey struct{}
+
+// operationStart is the start of a collection operation. Only its first
+// command is observed: the getMores of a cursor the operation reads belong
+// to the same operation.
+type operationStart struct {
+	at   time.Time
+	once sync.Once
+}
+
+func (m *metrics) Options() []mongodb.Option {
+	return []mongodb.Option{
+		mongodb.WithRawClientOptions(options.Client().SetMonitor(m.CommandMonitor()).SetPoolMonitor(m.PoolMonitor())),
+		mongodb.WithInterceptors(m.Interceptor()),
+	}
+}
+
+func (m *metrics) Interceptor() mongodb.Interceptor {
+	return func(ctx context.Context, op mongodb.OperationInfo, next mongodb.Invoker) error {
+		return next(context.WithValue(ctx, startKey{}, &operationStart{at: time.Now()}), op)
+	}
+}
+
+func (m *metrics) CommandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			m.collections.Store(requestKey{e.ConnectionID, e.RequestID}, collection(e))
+			// The wait covers server selection and connection check-out;
+			// the driver reports neither separately.
+			if start, ok := ctx.Value(startKey{}).(*operationStart); ok {
+				start.once.Do(func() {
+					m.wait.WithLabelValues(address(e.ConnectionID)).Observe(time.Since(start.at).Seconds())
+				})
+			}
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			m.finished(e.CommandFinishedEvent)
+			for _, code := range writeErrorCodes(e.Reply) {
+				m.errors.WithLabelValues(e.CommandName, code).Inc()
+			}
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			m.finished(e.CommandFinishedEvent)
+			m.errors.WithLabelValues(e.CommandName, failureCode(e.Failure)).Inc()
+		},
+	}
+}
+
+func (m *metrics) finished(e event.CommandFinishedEvent) {
+	coll, _ := m.collections.LoadAndDelete(requestKey{e.ConnectionID, e.RequestID})
+	name, _ := coll.(string)
+	m.duration.WithLabelValues(e.CommandName, name).Observe(time.Duration(e.DurationNanos).Seconds())
+}
+
+func (m *metrics) PoolMonitor() *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: m.poolEvent,
+	}
+}
+
+func (m *metrics) poolEvent(e *event.PoolEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	conns := m.conns[e.Address]
+	if conns == nil {
+		conns = make(map[uint64]bool)
+		m.conns[e.Address] = conns
+	}
+	switch e.Type {
+	case event.ConnectionCreated, event.ConnectionReturned:
+		conns[e.ConnectionID] = false
+	case event.GetSucceeded:
+		conns[e.ConnectionID] = true
+	case event.ConnectionClosed:
+		delete(conns, e.ConnectionID)
+	case event.PoolCleared:
+		// Idle connections are stale now and are closed instead of reused.
+		for id, out := range conns {
+			if !out {
+				delete(conns, id)
+			}
+		}
+	case event.PoolClosedEvent:
+		delete(m.conns, e.Address)
+		conns = nil
+	}
+
+	var checkedOut, idle int
+	for _, out := range conns {
+		if out {
+			checkedOut++
+		} else {
+			idle++
+		}
+	}
+	m.checkedOut.WithLabelValues(e.Address).Set(float64(checkedOut))
+	m.idle.WithLabelValues(e.Address).Set(float64(idle))
+}
+
+// collection returns the collection a command targets: the value of its
+// first element for most commands, or the collection field of a getMore.
+// Database commands report "".
+func collection(e *event.CommandStartedEvent) string {
+	if e.CommandName == "getMore" {
+		name, _ := e.Command.Lookup("collection").StringValueOK()
+		return name
+	}
+	elems, err := e.Command.Elements()
+	if err != nil || len(elems) == 0 {
+		return ""
+	}
+	name, _ := elems[0].Value().StringValueOK()
+	return name
+}
+
+// address strips the connection number from a driver connection id such as
+// "localhost:27017[-3]".
+func address(connID string) string {
+	addr, _, _ := strings.Cut(connID, "[")
+	return addr
+}
+
+// writeErrorCodes returns the codes of the write and write concern errors
+// in a reply. Such commands succeed as far as the driver is concerned.
+func writeErrorCodes(reply bson.Raw) []string {
+	var codes []string
+	if arr, ok := reply.Lookup("writeErrors").ArrayOK(); ok {
+		values, _ := arr.Values()
+		for _, v := range values {
+			if doc, ok := v.DocumentOK(); ok {
+				if code, ok := doc.Lookup("code").AsInt64OK(); ok {
+					codes = append(codes, strconv.FormatInt(code, 10))
+				}
+			}
+		}
+	}
+	if code, ok := reply.Lookup("writeConcernError", "code").AsInt64OK(); ok {
+		codes = append(codes, strconv.FormatInt(code, 10))
+	}
+	return codes
+}
+
+// failureCode returns the numeric code of a server error from the code name
+// the driver puts in parentheses at the start of the failure, e.g.
+// "(Unauthorized) ...", since the failed event carries no number. Names
+// missing from serverCodes and other failures, such as network errors, are
+// reported as "unknown".
+func failureCode(failure string) string {
+	if strings.HasPrefix(failure, "(") {
+		if name, _, ok := strings.Cut(failure[1:], ")"); ok {
+			if code, ok := serverCodes[name]; ok {
+				return strconv.Itoa(code)
+			}
+		}
+	}
+	return "unknown"
+}
+
+// serverCodes maps the names of common server errors to their codes.
+var serverCodes = map[string]int{
+	"BadValue":                        2,
+	"HostUnreachable":                 6,
+	"HostNotFound":                    7,
+	"FailedToParse":                   9,
+	"Unauthorized":                    13,
+	"AuthenticationFailed":            18,
+	"NamespaceNotFound":               26,
+	"CursorNotFound":                  43,
+	"NamespaceExists":                 48,
+	"MaxTimeMSExpired":                50,
+	"CommandNotFound":                 59,
+	"WriteConcernFailed":              64,
+	"ImmutableField":                  66,
+	"IndexOptionsConflict":            85,
+	"IndexKeySpecsConflict":           86,
+	"NetworkTimeout":                  89,
+	"ShutdownInProgress":              91,
+	"UnsatisfiableWriteConcern":       100,
+	"WriteConflict":                   112,
+	"DocumentValidationFailure":       121,
+	"FailedToSatisfyReadPreference":   133,
+	"PrimarySteppedDown":              189,
+	"RetryChangeStream":               234,
+	"NoSuchTransaction":               251,
+	"ExceededTimeLimit":               262,
+	"ChangeStreamFatalError":          280,
+	"ChangeStreamHistoryLost":         286,
+	"NotWritablePrimary":              10107,
+	"DuplicateKey":                    11000,
+	"InterruptedAtShutdown":           11600,
+	"Interrupted":                     11601,
+	"InterruptedDueToReplStateChange": 11602,
+	"NotPrimaryNoSecondaryOk":         13435,
+	"NotPrimaryOrSecondary":           13436,
+}
@@ -0,0 +1,121 @@
+package metrics_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/subratohld/mongodb"
+	"github.com/subratohld/mongodb/metrics"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+func TestCommandMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := metrics.New(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mon := m.CommandMonitor()
+	ctx := context.Background()
+
+	raw := func(v interface{}) bson.Raw {
+		b, err := bson.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	finished := func(cmd string, id int64) event.CommandFinishedEvent {
+		return event.CommandFinishedEvent{CommandName: cmd, RequestID: id, ConnectionID: "localhost:27017[-1]", DurationNanos: 2e6}
+	}
+
+	// The interceptor stamps the operation start used for the wait time;
+	// only the first command of the operation is observed.
+	err = m.Interceptor()(ctx, mongodb.OperationInfo{Name: "InsertOne"}, func(ctx context.Context, op mongodb.OperationInfo) error {
+		mon.Started(ctx, &event.CommandStartedEvent{CommandName: "insert", RequestID: 1, ConnectionID: "localhost:27017[-1]", Command: raw(bson.D{{Key: "insert", Value: "orders"}})})
+		mon.Started(ctx, &event.CommandStartedEvent{CommandName: "getMore", RequestID: 4, ConnectionID: "localhost:27018[-1]", Command: raw(bson.D{{Key: "getMore", Value: int64(1)}, {Key: "collection", Value: "orders"}})})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mon.Succeeded(ctx, &event.CommandSucceededEvent{
+		CommandFinishedEvent: finished("insert", 1),
+		Reply:                raw(bson.M{"ok": 1, "writeErrors": bson.A{bson.M{"index": 0, "code": 11000}}}),
+	})
+
+	mon.Started(ctx, &event.CommandStartedEvent{CommandName: "find", RequestID: 2, ConnectionID: "localhost:27017[-1]", Command: raw(bson.D{{Key: "find", Value: "orders"}})})
+	mon.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: finished("find", 2), Failure: "(Unauthorized) not authorized"})
+	mon.Started(ctx, &event.CommandStartedEvent{CommandName: "find", RequestID: 3, ConnectionID: "localhost:27017[-1]", Command: raw(bson.D{{Key: "find", Value: "orders"}})})
+	mon.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: finished("find", 3), Failure: "connection reset by peer"})
+
+	expected := `
+# HELP mongodb_command_errors_total Command errors by command name and numeric server error code, or unknown.
+# TYPE mongodb_command_errors_total counter
+mongodb_command_errors_total{code="11000",command="insert"} 1
+mongodb_command_errors_total{code="13",command="find"} 1
+mongodb_command_errors_total{code="unknown",command="find"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "mongodb_command_errors_total"); err != nil {
+		t.Error(err)
+	}
+	if n := testutil.CollectAndCount(reg, "mongodb_command_duration_seconds"); n != 2 {
+		t.Errorf("got %d duration series, want 2", n)
+	}
+	if n := testutil.CollectAndCount(reg, "mongodb_operation_wait_seconds"); n != 1 {
+		t.Errorf("got %d wait series, want 1", n)
+	}
+}
+
+func TestPoolMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := metrics.New(reg, metrics.WithNamespace("db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mon := m.PoolMonitor()
+
+	for _, e := range []struct {
+		typ string
+		id  uint64
+	}{
+		{event.ConnectionCreated, 1}, {event.ConnectionCreated, 2}, {event.ConnectionCreated, 3},
+		{event.GetSucceeded, 1}, {event.GetSucceeded, 2}, {event.ConnectionReturned, 2},
+	} {
+		mon.Event(&event.PoolEvent{Type: e.typ, Address: "localhost:27017", ConnectionID: e.id})
+	}
+	gauges := func(checkedOut, idle int) string {
+		return fmt.Sprintf(`
+# HELP db_pool_checked_out_connections Connections currently checked out of the pool, by server address.
+# TYPE db_pool_checked_out_connections gauge
+db_pool_checked_out_connections{address="localhost:27017"} %d
+# HELP db_pool_idle_connections Open connections waiting in the pool, by server address.
+# TYPE db_pool_idle_connections gauge
+db_pool_idle_connections{address="localhost:27017"} %d
+`, checkedOut, idle)
+	}
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(gauges(1, 2)), "db_pool_checked_out_connections", "db_pool_idle_connections"); err != nil {
+		t.Error(err)
+	}
+
+	// A cleared pool closes its idle connections rather than reusing them,
+	// and a checked-out connection may be closed without being returned.
+	mon.Event(&event.PoolEvent{Type: event.PoolCleared, Address: "localhost:27017"})
+	mon.Event(&event.PoolEvent{Type: event.ConnectionClosed, Address: "localhost:27017", ConnectionID: 2})
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(gauges(1, 0)), "db_pool_checked_out_connections", "db_pool_idle_connections"); err != nil {
+		t.Error(err)
+	}
+	mon.Event(&event.PoolEvent{Type: event.ConnectionClosed, Address: "localhost:27017", ConnectionID: 1})
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(gauges(0, 0)), "db_pool_checked_out_connections", "db_pool_idle_connections"); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := metrics.New(reg, metrics.WithNamespace("db")); err == nil {
+		t.Error("registering the collectors twice succeeded")
+	}
+}
@@ -0,0 +1,34 @@
+package mocks_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/subratohld/mongodb"
+	"github.com/subratohld/mongodb/mocks"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func countAdults(ctx context.Context, client mongodb.Client) (int64, error) {
+	coll := client.Database("testdb").Collection("users")
+	return coll.CountDocuments(ctx, bson.M{"age": bson.M{"$gte": 18}})
+}
+
+func TestMockChain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mocks.NewMockClient(ctrl)
+	db := mocks.NewMockDatabase(ctrl)
+	coll := mocks.NewMockCollection(ctrl)
+
+	client.EXPECT().Database("testdb").Return(db)
+	db.EXPECT().Collection("users").Return(coll)
+	coll.EXPECT().CountDocuments(gomock.Any(), bson.M{"age": bson.M{"$gte": 18}}).Return(int64(3), nil)
+
+	n, err := countAdults(context.TODO(), client)
+	if err != nil || n != 3 {
+		t.Errorf("countAdults = %d, %v", n, err)
+	}
+}
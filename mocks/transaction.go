@@ -0,0 +1,128 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: transaction.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	mongodb "github.com/subratohld/mongodb"
+	mongo "go.mongodb.org/mongo-driver/mongo"
+	options "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MockTxContext is a mock of TxContext interface.
+type MockTxContext struct {
+	ctrl     *gomock.Controller
+	recorder *MockTxContextMockRecorder
+}
+
+// MockTxContextMockRecorder is the mock recorder for MockTxContext.
+type MockTxContextMockRecorder struct {
+	mock *MockTxContext
+}
+
+// NewMockTxContext creates a new mock instance.
+func NewMockTxContext(ctrl *gomock.Controller) *MockTxContext {
+	mock := &MockTxContext{ctrl: ctrl}
+	mock.recorder = &MockTxContextMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTxContext) EXPECT() *MockTxContextMockRecorder {
+	return m.recorder
+}
+
+// Database mocks base method.
+func (m *MockTxContext) Database(name string, opts ...*options.DatabaseOptions) mongodb.Database {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{name}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Database", varargs...)
+	ret0, _ := ret[0].(mongodb.Database)
+	return ret0
+}
+
+// Database indicates an expected call of Database.
+func (mr *MockTxContextMockRecorder) Database(name interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{name}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Database", reflect.TypeOf((*MockTxContext)(nil).Database), varargs...)
+}
+
+// Deadline mocks base method.
+func (m *MockTxContext) Deadline() (time.Time, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deadline")
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Deadline indicates an expected call of Deadline.
+func (mr *MockTxContextMockRecorder) Deadline() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deadline", reflect.TypeOf((*MockTxContext)(nil).Deadline))
+}
+
+// Done mocks base method.
+func (m *MockTxContext) Done() <-chan struct{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Done")
+	ret0, _ := ret[0].(<-chan struct{})
+	return ret0
+}
+
+// Done indicates an expected call of Done.
+func (mr *MockTxContextMockRecorder) Done() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Done", reflect.TypeOf((*MockTxContext)(nil).Done))
+}
+
+// Err mocks base method.
+func (m *MockTxContext) Err() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Err")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Err indicates an expected call of Err.
+func (mr *MockTxContextMockRecorder) Err() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Err", reflect.TypeOf((*MockTxContext)(nil).Err))
+}
+
+// Session mocks base method.
+func (m *MockTxContext) Session() mongo.Session {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Session")
+	ret0, _ := ret[0].(mongo.Session)
+	return ret0
+}
+
+// Session indicates an expected call of Session.
+func (mr *MockTxContextMockRecorder) Session() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Session", reflect.TypeOf((*MockTxContext)(nil).Session))
+}
+
+// Value mocks base method.
+func (m *MockTxContext) Value(key any) any {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Value", key)
+	ret0, _ := ret[0].(any)
+	return ret0
+}
+
+// Value indicates an expected call of Value.
+func (mr *MockTxContextMockRecorder) Value(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Value", reflect.TypeOf((*MockTxContext)(nil).Value), key)
+}
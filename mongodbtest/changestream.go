@@ -0,0 +1,244 @@
+package mongodbtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/subratohld/mongodb"
+	"github.com/subratohld/mongodb/internal/bsonutil"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// event is an entry of the client's change log. Its position in the log is
+// its sequence number minus one.
+type event struct {
+	db, coll string
+	doc      bson.D
+	// post is the document after an update, served for
+	// options.UpdateLookup.
+	post bson.D
+}
+
+func resumeToken(seq int) bson.D {
+	return bson.D{{Key: "_data", Value: fmt.Sprintf("%016x", seq)}}
+}
+
+func parseResumeToken(token interface{}) (int, error) {
+	doc, err := toDoc(token)
+	if err != nil {
+		return 0, err
+	}
+	data, _ := bsonutil.Lookup(doc, "_data")
+	s, ok := data.(string)
+	if !ok {
+		return 0, errors.New("mongodbtest: malformed resume token")
+	}
+	seq, err := strconv.ParseInt(s, 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("mongodbtest: malformed resume token: %w", err)
+	}
+	return int(seq), nil
+}
+
+// emit appends a change event to the log and wakes up waiting streams. The
+// caller must hold c.mu.
+func (c *client) emit(db, coll, opType string, id interface{}, fields bson.D, post bson.D) {
+	doc := bson.D{
+		{Key: "_id", Value: resumeToken(len(c.events) + 1)},
+		{Key: "operationType", Value: opType},
+		{Key: "ns", Value: bson.D{{Key: "db", Value: db}, {Key: "coll", Value: coll}}},
+		{Key: "documentKey", Value: bson.D{{Key: "_id", Value: id}}},
+	}
+	doc = append(doc, fields...)
+	c.events = append(c.events, event{db: db, coll: coll, doc: doc, post: post})
+
+	if c.notify != nil {
+		close(c.notify)
+		c.notify = nil
+	}
+}
+
+// updateDescription compares the top-level fields of two versions of a
+// document.
+func updateDescription(before, after bson.D) bson.D {
+	updated := bson.D{}
+	for _, e := range after {
+		if old, ok := bsonutil.Lookup(before, e.Key); !ok || compareValues(old, e.Value) != 0 {
+			updated = append(updated, e)
+		}
+	}
+
+	removed := bson.A{}
+	for _, e := range before {
+		if _, ok := bsonutil.Lookup(after, e.Key); !ok {
+			removed = append(removed, e.Key)
+		}
+	}
+	return bson.D{{Key: "updatedFields", Value: updated}, {Key: "removedFields", Value: removed}}
+}
+
+func (c *client) watch(db, coll string, pipeline interface{}, opts ...*options.ChangeStreamOptions) (mongodb.ChangeStream, error) {
+	cs := &changeStream{client: c, db: db, coll: coll}
+
+	if pipeline != nil {
+		stages, err := bsonutil.ToDocs(pipeline)
+		if err != nil {
+			return nil, err
+		}
+		for _, stage := range stages {
+			match, ok := bsonutil.Lookup(stage, "$match")
+			f, isDoc := match.(bson.D)
+			if !ok || !isDoc || len(stage) != 1 {
+				return nil, fmt.Errorf("mongodbtest: only $match stages are supported in change streams: %w", ErrNotSupported)
+			}
+			cs.filters = append(cs.filters, f)
+		}
+	}
+
+	o := options.MergeChangeStreamOptions(opts...)
+	cs.lookup = o.FullDocument != nil && *o.FullDocument == options.UpdateLookup
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cs.pos = len(c.events)
+	token := o.ResumeAfter
+	if o.StartAfter != nil {
+		token = o.StartAfter
+	}
+	if token != nil {
+		seq, err := parseResumeToken(token)
+		if err != nil {
+			return nil, err
+		}
+		if seq > len(c.events) {
+			return nil, mongo.CommandError{Code: 280, Name: "ChangeStreamFatalError", Message: "cannot resume stream; the resume token was not found"}
+		}
+		cs.pos = seq
+	}
+	return cs, nil
+}
+
+// changeStream replays the client's change log. db and coll restrict the
+// namespaces it reports; empty values match everything.
+type changeStream struct {
+	client   *client
+	db, coll string
+	filters  []bson.D
+	lookup   bool
+
+	pos     int
+	current bson.D
+	closed  bool
+	err     error
+}
+
+func (cs *changeStream) Next(ctx context.Context) bool {
+	for {
+		if cs.TryNext(ctx) {
+			return true
+		}
+		if cs.closed || cs.err != nil {
+			return false
+		}
+
+		cs.client.mu.Lock()
+		if cs.pos < len(cs.client.events) {
+			cs.client.mu.Unlock()
+			continue
+		}
+		if cs.client.notify == nil {
+			cs.client.notify = make(chan struct{})
+		}
+		wait := cs.client.notify
+		cs.client.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			cs.err = ctx.Err()
+			return false
+		case <-wait:
+		}
+	}
+}
+
+func (cs *changeStream) TryNext(ctx context.Context) bool {
+	cs.current = nil
+	if cs.closed || cs.err != nil {
+		return false
+	}
+	if err := ctx.Err(); err != nil {
+		cs.err = err
+		return false
+	}
+
+	cs.client.mu.Lock()
+	defer cs.client.mu.Unlock()
+
+	for cs.pos < len(cs.client.events) {
+		ev := cs.client.events[cs.pos]
+		cs.pos++
+		if (cs.db != "" && ev.db != cs.db) || (cs.coll != "" && ev.coll != cs.coll) {
+			continue
+		}
+
+		doc := cloneDoc(ev.doc)
+		if cs.lookup && ev.post != nil {
+			doc = append(doc, bson.E{Key: "fullDocument", Value: cloneDoc(ev.post)})
+		}
+
+		ok, err := cs.match(doc)
+		if err != nil {
+			cs.err = err
+			return false
+		}
+		if ok {
+			cs.current = doc
+			return true
+		}
+	}
+	return false
+}
+
+func (cs *changeStream) match(doc bson.D) (bool, error) {
+	for _, f := range cs.filters {
+		if ok, err := matches(doc, f); err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (cs *changeStream) Decode(v interface{}) error {
+	if cs.current == nil {
+		return errNoCurrent
+	}
+	return decode(cs.current, v)
+}
+
+func (cs *changeStream) Err() error {
+	return cs.err
+}
+
+func (cs *changeStream) Close(ctx context.Context) error {
+	cs.closed = true
+	return nil
+}
+
+func (cs *changeStream) ID() int64 {
+	return 0
+}
+
+// ResumeToken returns the token of the last event the stream has read past,
+// or nil if it has not read any.
+func (cs *changeStream) ResumeToken() bson.Raw {
+	if cs.pos == 0 {
+		return nil
+	}
+	raw, _ := bson.Marshal(resumeToken(cs.pos))
+	return raw
+}
@@ -0,0 +1,152 @@
+// Package mongodbtest provides an in-memory implementation of the mongodb
+// Client, Database and Collection interfaces for unit tests that cannot
+// reach a live mongod.
+//
+// Documents are round-tripped through BSON on every read and write. Queries
+// support the common comparison, element, array and logical operators, and
+// updates support $set, $setOnInsert, $unset, $inc and $push. Unique
+// indexes are enforced, and change streams report insert, update, replace
+// and delete events. Operations that hand back driver-owned types which
+// cannot be constructed outside the driver (sessions, cursors) return
+// ErrNotSupported.
+package mongodbtest
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/subratohld/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrNotSupported = errors.New("mongodbtest: operation not supported by the in-memory client")
+
+// NewClient returns an empty in-memory client.
+func NewClient() mongodb.Client {
+	return &client{
+		dbs: make(map[string]*dbStore),
+	}
+}
+
+type dbStore struct {
+	colls map[string]*collStore
+}
+
+type collStore struct {
+	docs    []bson.D
+	indexes []index
+}
+
+type client struct {
+	mu  sync.Mutex
+	dbs map[string]*dbStore
+
+	// events is the change log read by change streams; notify is closed
+	// when an event is appended.
+	events []event
+	notify chan struct{}
+}
+
+func (c *client) Disconnect(ctx context.Context) error {
+	return nil
+}
+
+func (c *client) Database(name string, opts ...*options.DatabaseOptions) mongodb.Database {
+	return newDB(c, name, opts...)
+}
+
+func (c *client) ListDatabases(ctx context.Context, filter interface{}, opts ...*options.ListDatabasesOptions) (mongo.ListDatabasesResult, error) {
+	f, err := toDoc(filter)
+	if err != nil {
+		return mongo.ListDatabasesResult{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var res mongo.ListDatabasesResult
+	for _, name := range c.databaseNames() {
+		spec := mongo.DatabaseSpecification{Name: name, Empty: len(c.dbs[name].colls) == 0}
+		ok, err := matches(bson.D{{Key: "name", Value: spec.Name}, {Key: "sizeOnDisk", Value: spec.SizeOnDisk}, {Key: "empty", Value: spec.Empty}}, f)
+		if err != nil {
+			return mongo.ListDatabasesResult{}, err
+		}
+		if ok {
+			res.Databases = append(res.Databases, spec)
+		}
+	}
+	return res, nil
+}
+
+func (c *client) ListDatabaseNames(ctx context.Context, filter interface{}, opts ...*options.ListDatabasesOptions) ([]string, error) {
+	res, err := c.ListDatabases(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(res.Databases))
+	for i, spec := range res.Databases {
+		names[i] = spec.Name
+	}
+	return names, nil
+}
+
+func (c *client) StartSession(opts ...*options.SessionOptions) (mongo.Session, error) {
+	return nil, ErrNotSupported
+}
+
+func (c *client) UseSession(ctx context.Context, fn func(mongo.SessionContext) error) error {
+	return ErrNotSupported
+}
+
+func (c *client) UseSessionWithOptions(ctx context.Context, opts *options.SessionOptions, fn func(mongo.SessionContext) error) error {
+	return ErrNotSupported
+}
+
+func (c *client) NumberSessionsInProgress() int {
+	return 0
+}
+
+func (c *client) Watch(ctx context.Context, pipeline interface{}, opts ...*options.ChangeStreamOptions) (mongodb.ChangeStream, error) {
+	return c.watch("", "", pipeline, opts...)
+}
+
+// databaseNames returns the sorted names of all databases holding at least
+// one collection. The caller must hold c.mu.
+func (c *client) databaseNames() []string {
+	names := make([]string, 0, len(c.dbs))
+	for name, db := range c.dbs {
+		if len(db.colls) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// store returns the documents of db.coll, creating the namespace when create
+// is set. The caller must hold c.mu.
+func (c *client) store(db, coll string, create bool) *collStore {
+	d, ok := c.dbs[db]
+	if !ok {
+		if !create {
+			return nil
+		}
+		d = &dbStore{colls: make(map[string]*collStore)}
+		c.dbs[db] = d
+	}
+
+	s, ok := d.colls[coll]
+	if !ok {
+		if !create {
+			return nil
+		}
+		s = &collStore{}
+		d.colls[coll] = s
+	}
+	return s
+}
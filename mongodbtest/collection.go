@@ -0,0 +1,780 @@
+package mongodbtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/subratohld/mongodb"
+	"github.com/subratohld/mongodb/internal/bsonutil"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func newCollection(db *database, name string) mongodb.Collection {
+	return &collection{
+		db:   db,
+		name: name,
+	}
+}
+
+type collection struct {
+	db   *database
+	name string
+}
+
+func (coll *collection) lock() func() {
+	coll.db.client.mu.Lock()
+	return coll.db.client.mu.Unlock
+}
+
+func (coll *collection) store(create bool) *collStore {
+	s := coll.db.client.store(coll.db.name, coll.name, create)
+	if s == nil {
+		return &collStore{}
+	}
+	return s
+}
+
+func (coll *collection) Database() mongodb.Database {
+	return coll.db
+}
+
+func (coll *collection) Name() string {
+	return coll.name
+}
+
+func (coll *collection) Drop(ctx context.Context) error {
+	defer coll.lock()()
+
+	if d, ok := coll.db.client.dbs[coll.db.name]; ok {
+		delete(d.colls, coll.name)
+	}
+	return nil
+}
+
+func (coll *collection) Indexes() mongodb.IndexView {
+	return newIndexView(coll)
+}
+
+func (coll *collection) InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (interface{}, error) {
+	defer coll.lock()()
+	return coll.insert(coll.store(true), document)
+}
+
+func (coll *collection) InsertMany(ctx context.Context, documents []interface{}, opts ...*options.InsertManyOptions) ([]interface{}, error) {
+	if len(documents) == 0 {
+		return nil, mongo.ErrEmptySlice
+	}
+
+	defer coll.lock()()
+
+	s := coll.store(true)
+	ids := make([]interface{}, len(documents))
+	for i, doc := range documents {
+		id, err := coll.insert(s, doc)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+func (coll *collection) UpdateByID(ctx context.Context, id interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return coll.UpdateOne(ctx, bson.D{{Key: "_id", Value: id}}, update, opts...)
+}
+
+func (coll *collection) UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	defer coll.lock()()
+	return coll.update(filter, update, upsert(options.MergeUpdateOptions(opts...).Upsert), false)
+}
+
+func (coll *collection) UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	defer coll.lock()()
+	return coll.update(filter, update, upsert(options.MergeUpdateOptions(opts...).Upsert), true)
+}
+
+func (coll *collection) DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	defer coll.lock()()
+
+	n, err := coll.delete(filter, false)
+	if err != nil {
+		return nil, err
+	}
+	return &mongo.DeleteResult{DeletedCount: n}, nil
+}
+
+func (coll *collection) DeleteMany(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	defer coll.lock()()
+
+	n, err := coll.delete(filter, true)
+	if err != nil {
+		return nil, err
+	}
+	return &mongo.DeleteResult{DeletedCount: n}, nil
+}
+
+func (coll *collection) FindOne(ctx context.Context, filter interface{}, result interface{}, opts ...*options.FindOneOptions) error {
+	o := options.MergeFindOneOptions(opts...)
+	docs, err := coll.query(filter, o.Sort, o.Projection, int64Value(o.Skip), 1)
+	if err != nil {
+		return err
+	}
+	if len(docs) == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return decode(docs[0], result)
+}
+
+func (coll *collection) Find(ctx context.Context, filter interface{}, results interface{}, opts ...*options.FindOptions) error {
+	o := options.MergeFindOptions(opts...)
+	docs, err := coll.query(filter, o.Sort, o.Projection, int64Value(o.Skip), int64Value(o.Limit))
+	if err != nil {
+		return err
+	}
+	return decodeAll(docs, results)
+}
+
+func (coll *collection) FindIterator(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (mongodb.Iterator, error) {
+	o := options.MergeFindOptions(opts...)
+	docs, err := coll.query(filter, o.Sort, o.Projection, int64Value(o.Skip), int64Value(o.Limit))
+	if err != nil {
+		return nil, err
+	}
+	return newIterator(docs, o.BatchSize), nil
+}
+
+func (coll *collection) FindEach(ctx context.Context, filter interface{}, fn func(decode func(v interface{}) error) error, opts ...*options.FindOptions) error {
+	it, err := coll.FindIterator(ctx, filter, opts...)
+	if err != nil {
+		return err
+	}
+	return mongodb.Each(ctx, it, fn)
+}
+
+func (coll *collection) FindOneAndDelete(ctx context.Context, filter interface{}, target interface{}, opts ...*options.FindOneAndDeleteOptions) error {
+	o := options.MergeFindOneAndDeleteOptions(opts...)
+
+	unlock := coll.lock()
+	s := coll.store(false)
+	idx, err := coll.selectDocs(s, filter, o.Sort)
+	if err != nil || len(idx) == 0 {
+		unlock()
+		if err == nil {
+			err = mongo.ErrNoDocuments
+		}
+		return err
+	}
+	doc := s.docs[idx[0]]
+	coll.remove(s, idx)
+	unlock()
+
+	return decodeProjected(doc, o.Projection, target)
+}
+
+func (coll *collection) FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, target interface{}, opts ...*options.FindOneAndUpdateOptions) error {
+	o := options.MergeFindOneAndUpdateOptions(opts...)
+	return coll.findOneAndModify(filter, o.Sort, o.Projection, upsert(o.Upsert), returnAfter(o.ReturnDocument), target,
+		func(filter interface{}) (*mongo.UpdateResult, error) {
+			return coll.update(filter, update, upsert(o.Upsert), false)
+		})
+}
+
+func (coll *collection) FindOneAndReplace(ctx context.Context, filter interface{}, replace interface{}, target interface{}, opts ...*options.FindOneAndReplaceOptions) error {
+	o := options.MergeFindOneAndReplaceOptions(opts...)
+	return coll.findOneAndModify(filter, o.Sort, o.Projection, upsert(o.Upsert), returnAfter(o.ReturnDocument), target,
+		func(filter interface{}) (*mongo.UpdateResult, error) {
+			return coll.replace(filter, replace, upsert(o.Upsert))
+		})
+}
+
+// findOneAndModify applies modify to the first document matching filter in
+// sortSpec order and decodes the document from before or after the change
+// into target.
+func (coll *collection) findOneAndModify(filter, sortSpec, projection interface{}, upsert, after bool, target interface{}, modify func(filter interface{}) (*mongo.UpdateResult, error)) error {
+	unlock := coll.lock()
+
+	idx, err := coll.selectDocs(coll.store(false), filter, sortSpec)
+	if err != nil {
+		unlock()
+		return err
+	}
+	if len(idx) == 0 && !upsert {
+		unlock()
+		return mongo.ErrNoDocuments
+	}
+
+	var before bson.D
+	if len(idx) > 0 {
+		before = coll.store(false).docs[idx[0]]
+		filter = bson.D{{Key: "_id", Value: mustID(before)}}
+	}
+
+	res, err := modify(filter)
+	if err != nil {
+		unlock()
+		return err
+	}
+
+	doc := before
+	if after {
+		id := res.UpsertedID
+		if before != nil {
+			id = mustID(before)
+		}
+		doc = coll.findByID(id)
+	}
+	unlock()
+
+	if doc == nil {
+		return mongo.ErrNoDocuments
+	}
+	if target == nil {
+		return nil
+	}
+	return decodeProjected(doc, projection, target)
+}
+
+func (coll *collection) ReplaceOne(ctx context.Context, filter interface{}, replacement interface{}, opts ...*options.ReplaceOptions) (*mongo.UpdateResult, error) {
+	defer coll.lock()()
+	return coll.replace(filter, replacement, upsert(options.MergeReplaceOptions(opts...).Upsert))
+}
+
+// Aggregate supports the $match, $sort, $skip, $limit, $project and $count
+// stages.
+func (coll *collection) Aggregate(ctx context.Context, pipeline interface{}, target interface{}, opts ...*options.AggregateOptions) error {
+	docs, err := coll.aggregate(pipeline)
+	if err != nil {
+		return err
+	}
+	return decodeAll(docs, target)
+}
+
+func (coll *collection) AggregateIterator(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (mongodb.Iterator, error) {
+	docs, err := coll.aggregate(pipeline)
+	if err != nil {
+		return nil, err
+	}
+	return newIterator(docs, options.MergeAggregateOptions(opts...).BatchSize), nil
+}
+
+func (coll *collection) BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	if len(models) == 0 {
+		return nil, mongo.ErrEmptySlice
+	}
+
+	defer coll.lock()()
+
+	res := &mongo.BulkWriteResult{UpsertedIDs: make(map[int64]interface{})}
+	for i, model := range models {
+		var ur *mongo.UpdateResult
+		var err error
+		switch m := model.(type) {
+		case *mongo.InsertOneModel:
+			if _, err = coll.insert(coll.store(true), m.Document); err == nil {
+				res.InsertedCount++
+			}
+		case *mongo.UpdateOneModel:
+			ur, err = coll.update(m.Filter, m.Update, upsert(m.Upsert), false)
+		case *mongo.UpdateManyModel:
+			ur, err = coll.update(m.Filter, m.Update, upsert(m.Upsert), true)
+		case *mongo.ReplaceOneModel:
+			ur, err = coll.replace(m.Filter, m.Replacement, upsert(m.Upsert))
+		case *mongo.DeleteOneModel:
+			var n int64
+			n, err = coll.delete(m.Filter, false)
+			res.DeletedCount += n
+		case *mongo.DeleteManyModel:
+			var n int64
+			n, err = coll.delete(m.Filter, true)
+			res.DeletedCount += n
+		default:
+			err = fmt.Errorf("mongodbtest: unsupported write model %T", model)
+		}
+		if err != nil {
+			return res, err
+		}
+
+		if ur != nil {
+			res.MatchedCount += ur.MatchedCount
+			res.ModifiedCount += ur.ModifiedCount
+			res.UpsertedCount += ur.UpsertedCount
+			if ur.UpsertedID != nil {
+				res.UpsertedIDs[int64(i)] = ur.UpsertedID
+			}
+		}
+	}
+	return res, nil
+}
+
+// Clone returns a handle on the same collection. Collection options are
+// ignored.
+func (coll *collection) Clone(opts ...*options.CollectionOptions) (mongodb.Collection, error) {
+	return newCollection(coll.db, coll.name), nil
+}
+
+func (coll *collection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	o := options.MergeCountOptions(opts...)
+	docs, err := coll.query(filter, nil, nil, int64Value(o.Skip), int64Value(o.Limit))
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(docs)), nil
+}
+
+func (coll *collection) Distinct(ctx context.Context, fieldName string, filter interface{}, opts ...*options.DistinctOptions) ([]interface{}, error) {
+	docs, err := coll.query(filter, nil, nil, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	values := []interface{}{}
+	for _, doc := range docs {
+		for _, v := range lookupPath(doc, fieldName) {
+			items := []interface{}{v}
+			if arr, ok := v.(primitive.A); ok {
+				items = arr
+			}
+			for _, item := range items {
+				if !containsValue(values, item) {
+					values = append(values, item)
+				}
+			}
+		}
+	}
+	return values, nil
+}
+
+func (coll *collection) EstimatedDocumentCount(ctx context.Context, opts ...*options.EstimatedDocumentCountOptions) (int64, error) {
+	defer coll.lock()()
+	return int64(len(coll.store(false).docs)), nil
+}
+
+func (coll *collection) Watch(ctx context.Context, pipeline interface{}, opts ...*options.ChangeStreamOptions) (mongodb.ChangeStream, error) {
+	return coll.db.client.watch(coll.db.name, coll.name, pipeline, opts...)
+}
+
+func (coll *collection) aggregate(pipeline interface{}) ([]bson.D, error) {
+	stages, err := bsonutil.ToDocs(pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := coll.query(nil, nil, nil, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, stage := range stages {
+		if len(stage) != 1 {
+			return nil, errors.New("mongodbtest: a pipeline stage specification object must contain exactly one field")
+		}
+		if docs, err = applyStage(docs, stage[0]); err != nil {
+			return nil, err
+		}
+	}
+	return docs, nil
+}
+
+// query returns projected copies of the matching documents.
+func (coll *collection) query(filter, sortSpec, projection interface{}, skip, limit int64) ([]bson.D, error) {
+	unlock := coll.lock()
+	s := coll.store(false)
+	idx, err := coll.selectDocs(s, filter, sortSpec)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+
+	docs := make([]bson.D, 0, len(idx))
+	for _, i := range idx {
+		docs = append(docs, cloneDoc(s.docs[i]))
+	}
+	unlock()
+
+	docs = paginate(docs, skip, limit)
+	if projection == nil {
+		return docs, nil
+	}
+
+	proj, err := toDoc(projection)
+	if err != nil {
+		return nil, err
+	}
+	for i, doc := range docs {
+		if docs[i], err = project(doc, proj); err != nil {
+			return nil, err
+		}
+	}
+	return docs, nil
+}
+
+// selectDocs returns the positions in s of the documents matching filter,
+// ordered by sortSpec. The caller must hold the client lock.
+func (coll *collection) selectDocs(s *collStore, filter, sortSpec interface{}) ([]int, error) {
+	f, err := toDoc(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var idx []int
+	for i, doc := range s.docs {
+		ok, err := matches(doc, f)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			idx = append(idx, i)
+		}
+	}
+
+	if sortSpec == nil {
+		return idx, nil
+	}
+	spec, err := toDoc(sortSpec)
+	if err != nil {
+		return nil, err
+	}
+	less, err := sortLess(spec)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		return less(s.docs[idx[i]], s.docs[idx[j]])
+	})
+	return idx, nil
+}
+
+// insert stores document and returns its _id, generating an ObjectID when
+// the document has none. The caller must hold the client lock.
+func (coll *collection) insert(s *collStore, document interface{}) (interface{}, error) {
+	doc, err := toDoc(document)
+	if err != nil {
+		return nil, err
+	}
+
+	id, ok := bsonutil.Lookup(doc, "_id")
+	if !ok {
+		id = primitive.NewObjectID()
+		doc = append(bson.D{{Key: "_id", Value: id}}, doc...)
+	}
+
+	for _, existing := range s.docs {
+		if equalValues(mustID(existing), id) {
+			return nil, coll.duplicateKeyError(idIndexName, bson.D{{Key: "_id", Value: id}})
+		}
+	}
+	if err := coll.checkUnique(s, doc, -1); err != nil {
+		return nil, err
+	}
+
+	s.docs = append(s.docs, doc)
+	coll.emit("insert", id, bson.D{{Key: "fullDocument", Value: doc}}, nil)
+	return id, nil
+}
+
+// update applies update to the first or all matching documents. The caller
+// must hold the client lock.
+func (coll *collection) update(filter, update interface{}, upsert, many bool) (*mongo.UpdateResult, error) {
+	f, err := toDoc(filter)
+	if err != nil {
+		return nil, err
+	}
+	u, err := toDoc(update)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateUpdate(u); err != nil {
+		return nil, err
+	}
+
+	s := coll.store(false)
+	idx, err := coll.selectDocs(s, f, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !many && len(idx) > 1 {
+		idx = idx[:1]
+	}
+
+	res := &mongo.UpdateResult{MatchedCount: int64(len(idx))}
+	for _, i := range idx {
+		doc, err := applyUpdate(s.docs[i], u, false)
+		if err != nil {
+			return nil, err
+		}
+		if compareValues(doc, s.docs[i]) == 0 {
+			continue
+		}
+		if err := coll.checkUnique(s, doc, i); err != nil {
+			return nil, err
+		}
+		res.ModifiedCount++
+		coll.emit("update", mustID(doc), bson.D{{Key: "updateDescription", Value: updateDescription(s.docs[i], doc)}}, doc)
+		s.docs[i] = doc
+	}
+
+	if len(idx) == 0 && upsert {
+		seed, err := upsertSeed(f)
+		if err != nil {
+			return nil, err
+		}
+		doc, err := applyUpdate(seed, u, true)
+		if err != nil {
+			return nil, err
+		}
+		if res.UpsertedID, err = coll.insert(coll.store(true), doc); err != nil {
+			return nil, err
+		}
+		res.UpsertedCount = 1
+	}
+	return res, nil
+}
+
+// replace swaps the first matching document for replacement, keeping its
+// _id. The caller must hold the client lock.
+func (coll *collection) replace(filter, replacement interface{}, upsert bool) (*mongo.UpdateResult, error) {
+	f, err := toDoc(filter)
+	if err != nil {
+		return nil, err
+	}
+	r, err := toDoc(replacement)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateReplacement(r); err != nil {
+		return nil, err
+	}
+
+	s := coll.store(false)
+	idx, err := coll.selectDocs(s, f, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(idx) == 0 {
+		if !upsert {
+			return &mongo.UpdateResult{}, nil
+		}
+		if _, ok := bsonutil.Lookup(r, "_id"); !ok {
+			if id, ok := bsonutil.Lookup(f, "_id"); ok {
+				r = append(bson.D{{Key: "_id", Value: id}}, r...)
+			}
+		}
+		id, err := coll.insert(coll.store(true), r)
+		if err != nil {
+			return nil, err
+		}
+		return &mongo.UpdateResult{UpsertedCount: 1, UpsertedID: id}, nil
+	}
+
+	cur := s.docs[idx[0]]
+	id := mustID(cur)
+	doc := bson.D{{Key: "_id", Value: id}}
+	for _, e := range r {
+		if e.Key == "_id" {
+			if !equalValues(e.Value, id) {
+				return nil, errors.New("mongodbtest: the _id field cannot be changed by a replacement")
+			}
+			continue
+		}
+		doc = append(doc, e)
+	}
+
+	if err := coll.checkUnique(s, doc, idx[0]); err != nil {
+		return nil, err
+	}
+
+	res := &mongo.UpdateResult{MatchedCount: 1}
+	if compareValues(doc, cur) != 0 {
+		res.ModifiedCount = 1
+	}
+	s.docs[idx[0]] = doc
+	coll.emit("replace", id, bson.D{{Key: "fullDocument", Value: doc}}, nil)
+	return res, nil
+}
+
+// delete removes the first or all matching documents. The caller must hold
+// the client lock.
+func (coll *collection) delete(filter interface{}, many bool) (int64, error) {
+	s := coll.store(false)
+	idx, err := coll.selectDocs(s, filter, nil)
+	if err != nil {
+		return 0, err
+	}
+	if !many && len(idx) > 1 {
+		idx = idx[:1]
+	}
+
+	coll.remove(s, idx)
+	return int64(len(idx)), nil
+}
+
+// remove deletes the documents at the positions in idx. The caller must hold
+// the client lock.
+func (coll *collection) remove(s *collStore, idx []int) {
+	removed := make(map[int]bool, len(idx))
+	for _, i := range idx {
+		removed[i] = true
+	}
+	kept := make([]bson.D, 0, len(s.docs)-len(idx))
+	for i, doc := range s.docs {
+		if removed[i] {
+			coll.emit("delete", mustID(doc), nil, nil)
+			continue
+		}
+		kept = append(kept, doc)
+	}
+	s.docs = kept
+}
+
+// emit records a change event for the collection. The caller must hold the
+// client lock.
+func (coll *collection) emit(opType string, id interface{}, fields bson.D, post bson.D) {
+	coll.db.client.emit(coll.db.name, coll.name, opType, id, fields, post)
+}
+
+func (coll *collection) duplicateKeyError(index string, key bson.D) error {
+	fields := make([]string, len(key))
+	for i, e := range key {
+		fields[i] = fmt.Sprintf("%s: %v", e.Key, e.Value)
+	}
+	return mongo.WriteException{
+		WriteErrors: mongo.WriteErrors{{
+			Code:    11000,
+			Message: fmt.Sprintf("E11000 duplicate key error collection: %s.%s index: %s dup key: { %s }", coll.db.name, coll.name, index, strings.Join(fields, ", ")),
+		}},
+	}
+}
+
+func applyStage(docs []bson.D, stage bson.E) ([]bson.D, error) {
+	switch stage.Key {
+	case "$match":
+		f, ok := stage.Value.(primitive.D)
+		if !ok {
+			return nil, errors.New("mongodbtest: $match requires a document")
+		}
+		out := docs[:0]
+		for _, doc := range docs {
+			ok, err := matches(doc, f)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				out = append(out, doc)
+			}
+		}
+		return out, nil
+	case "$sort":
+		spec, ok := stage.Value.(primitive.D)
+		if !ok {
+			return nil, errors.New("mongodbtest: $sort requires a document")
+		}
+		return docs, sortDocs(docs, spec)
+	case "$skip", "$limit":
+		n, ok := intValue(stage.Value)
+		if !ok {
+			return nil, fmt.Errorf("mongodbtest: %s requires an integer", stage.Key)
+		}
+		if stage.Key == "$skip" {
+			return paginate(docs, n, 0), nil
+		}
+		return paginate(docs, 0, n), nil
+	case "$project":
+		spec, ok := stage.Value.(primitive.D)
+		if !ok {
+			return nil, errors.New("mongodbtest: $project requires a document")
+		}
+		for i, doc := range docs {
+			var err error
+			if docs[i], err = project(doc, spec); err != nil {
+				return nil, err
+			}
+		}
+		return docs, nil
+	case "$count":
+		field, ok := stage.Value.(string)
+		if !ok {
+			return nil, errors.New("mongodbtest: $count requires a string")
+		}
+		if len(docs) == 0 {
+			return nil, nil
+		}
+		return []bson.D{{{Key: field, Value: int32(len(docs))}}}, nil
+	}
+	return nil, fmt.Errorf("mongodbtest: unsupported pipeline stage %q: %w", stage.Key, ErrNotSupported)
+}
+
+func paginate(docs []bson.D, skip, limit int64) []bson.D {
+	if skip > 0 {
+		if skip >= int64(len(docs)) {
+			return docs[:0]
+		}
+		docs = docs[skip:]
+	}
+	if limit < 0 {
+		limit = -limit
+	}
+	if limit > 0 && limit < int64(len(docs)) {
+		docs = docs[:limit]
+	}
+	return docs
+}
+
+func decodeProjected(doc bson.D, projection interface{}, target interface{}) error {
+	if projection != nil {
+		proj, err := toDoc(projection)
+		if err != nil {
+			return err
+		}
+		if doc, err = project(doc, proj); err != nil {
+			return err
+		}
+	}
+	return decode(doc, target)
+}
+
+func containsValue(values []interface{}, v interface{}) bool {
+	for _, existing := range values {
+		if equalValues(existing, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// findByID returns the stored document with the given _id. The caller must
+// hold the client lock.
+func (coll *collection) findByID(id interface{}) bson.D {
+	for _, doc := range coll.store(false).docs {
+		if equalValues(mustID(doc), id) {
+			return doc
+		}
+	}
+	return nil
+}
+
+func returnAfter(rd *options.ReturnDocument) bool {
+	return rd != nil && *rd == options.After
+}
+
+func mustID(doc bson.D) interface{} {
+	id, _ := bsonutil.Lookup(doc, "_id")
+	return id
+}
+
+func upsert(b *bool) bool {
+	return b != nil && *b
+}
+
+func int64Value(n *int64) int64 {
+	if n == nil {
+		return 0
+	}
+	return *n
+}
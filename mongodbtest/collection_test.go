@@ -0,0 +1,436 @@
+package mongodbtest_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/subratohld/mongodb"
+	"github.com/subratohld/mongodb/mongodbtest"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type User struct {
+	Id   string   `bson:"_id,omitempty"`
+	Name string   `bson:"name,omitempty"`
+	Age  int      `bson:"age,omitempty"`
+	Tags []string `bson:"tags,omitempty"`
+}
+
+func seed(t *testing.T) mongodb.Collection {
+	t.Helper()
+
+	coll := mongodbtest.NewClient().Database("testdb").Collection("users")
+	users := []interface{}{
+		User{Name: "Shekhar", Age: 25, Tags: []string{"admin"}},
+		User{Name: "Priya", Age: 22},
+		User{Name: "Subrato", Age: 31, Tags: []string{"dev", "ops"}},
+	}
+	if _, err := coll.InsertMany(context.TODO(), users); err != nil {
+		t.Fatalf("InsertMany: %v", err)
+	}
+	return coll
+}
+
+func names(users []User) []string {
+	out := make([]string, len(users))
+	for i, u := range users {
+		out[i] = u.Name
+	}
+	return out
+}
+
+func TestFindFilters(t *testing.T) {
+	coll := seed(t)
+
+	tests := []struct {
+		name   string
+		filter interface{}
+		want   []string
+	}{
+		{"eq", bson.M{"name": "Priya"}, []string{"Priya"}},
+		{"gt", bson.M{"age": bson.M{"$gt": 24}}, []string{"Shekhar", "Subrato"}},
+		{"range", bson.M{"age": bson.M{"$gte": 22, "$lt": 31}}, []string{"Shekhar", "Priya"}},
+		{"in", bson.M{"name": bson.M{"$in": bson.A{"Priya", "Subrato"}}}, []string{"Priya", "Subrato"}},
+		{"array element", bson.M{"tags": "ops"}, []string{"Subrato"}},
+		{"exists", bson.M{"tags": bson.M{"$exists": false}}, []string{"Priya"}},
+		{"or", bson.M{"$or": bson.A{bson.M{"age": 22}, bson.M{"tags": "admin"}}}, []string{"Shekhar", "Priya"}},
+		{"and", bson.D{{Key: "$and", Value: bson.A{bson.M{"age": bson.M{"$gt": 20}}, bson.M{"name": bson.M{"$ne": "Priya"}}}}}, []string{"Shekhar", "Subrato"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var users []User
+			if err := coll.Find(context.TODO(), tt.filter, &users); err != nil {
+				t.Fatalf("Find: %v", err)
+			}
+			if got := names(users); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindSortSkipLimitProjection(t *testing.T) {
+	coll := seed(t)
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "age", Value: -1}}).
+		SetSkip(1).
+		SetLimit(1).
+		SetProjection(bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 0}})
+
+	var users []User
+	if err := coll.Find(context.TODO(), bson.M{}, &users, opts); err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if want := []User{{Name: "Shekhar"}}; !reflect.DeepEqual(users, want) {
+		t.Errorf("got %+v, want %+v", users, want)
+	}
+}
+
+func TestUpdateOperators(t *testing.T) {
+	coll := seed(t)
+	ctx := context.TODO()
+
+	update := bson.M{
+		"$set":   bson.M{"name": "Priya K"},
+		"$inc":   bson.M{"age": 2},
+		"$push":  bson.M{"tags": bson.M{"$each": bson.A{"qa", "dev"}}},
+		"$unset": bson.M{"missing": ""},
+	}
+	res, err := coll.UpdateOne(ctx, bson.M{"name": "Priya"}, update)
+	if err != nil {
+		t.Fatalf("UpdateOne: %v", err)
+	}
+	if res.MatchedCount != 1 || res.ModifiedCount != 1 {
+		t.Fatalf("got matched=%d modified=%d", res.MatchedCount, res.ModifiedCount)
+	}
+
+	var user User
+	if err := coll.FindOne(ctx, bson.M{"name": "Priya K"}, &user); err != nil {
+		t.Fatalf("FindOne: %v", err)
+	}
+	if user.Age != 24 || !reflect.DeepEqual(user.Tags, []string{"qa", "dev"}) {
+		t.Errorf("got %+v", user)
+	}
+
+	if _, err := coll.UpdateOne(ctx, bson.M{"name": "Priya K"}, bson.M{"name": "x"}); err == nil {
+		t.Error("expected an error for an update without operators")
+	}
+}
+
+func TestUpsertAndDelete(t *testing.T) {
+	coll := seed(t)
+	ctx := context.TODO()
+
+	res, err := coll.UpdateOne(ctx, bson.M{"name": "Asha"}, bson.M{"$set": bson.M{"age": 40}}, options.Update().SetUpsert(true))
+	if err != nil {
+		t.Fatalf("UpdateOne: %v", err)
+	}
+	if res.UpsertedCount != 1 || res.UpsertedID == nil {
+		t.Fatalf("expected an upsert, got %+v", res)
+	}
+
+	n, err := coll.CountDocuments(ctx, map[string]interface{}{"age": map[string]interface{}{"$gte": 25}})
+	if err != nil || n != 3 {
+		t.Fatalf("CountDocuments = %d, %v", n, err)
+	}
+
+	del, err := coll.DeleteMany(ctx, bson.M{"age": bson.M{"$gte": 25}})
+	if err != nil || del.DeletedCount != 3 {
+		t.Fatalf("DeleteMany = %+v, %v", del, err)
+	}
+
+	if err := coll.FindOne(ctx, bson.M{"name": "Asha"}, &User{}); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("FindOne err = %v, want mongo.ErrNoDocuments", err)
+	}
+}
+
+func TestDuplicateKey(t *testing.T) {
+	coll := mongodbtest.NewClient().Database("testdb").Collection("users")
+	ctx := context.TODO()
+
+	if _, err := coll.BulkWrite(ctx, []mongo.WriteModel{mongo.NewInsertOneModel().SetDocument(bson.M{"_id": 1})}); err != nil {
+		t.Fatalf("BulkWrite: %v", err)
+	}
+	_, err := coll.BulkWrite(ctx, []mongo.WriteModel{mongo.NewInsertOneModel().SetDocument(bson.M{"_id": 1})})
+	if !mongo.IsDuplicateKeyError(err) {
+		t.Errorf("err = %v, want a duplicate key error", err)
+	}
+}
+
+func TestAggregateAndDistinct(t *testing.T) {
+	coll := seed(t)
+	ctx := context.TODO()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"age": bson.M{"$gt": 22}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "name", Value: 1}}}},
+		{{Key: "$project", Value: bson.M{"name": 1}}},
+	}
+	var users []User
+	if err := coll.Aggregate(ctx, pipeline, &users); err != nil {
+		t.Fatalf("Aggregate: %v", err)
+	}
+	if got, want := names(users), []string{"Shekhar", "Subrato"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	tags, err := coll.Distinct(ctx, "tags", nil)
+	if err != nil {
+		t.Fatalf("Distinct: %v", err)
+	}
+	if want := []interface{}{"admin", "dev", "ops"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("got %v, want %v", tags, want)
+	}
+
+	dbs, err := coll.Database().Client().ListDatabaseNames(ctx, bson.M{})
+	if err != nil || !reflect.DeepEqual(dbs, []string{"testdb"}) {
+		t.Errorf("ListDatabaseNames = %v, %v", dbs, err)
+	}
+}
+
+func TestInsertPreservesIDType(t *testing.T) {
+	coll := mongodbtest.NewClient().Database("testdb").Collection("users")
+	ctx := context.TODO()
+
+	id, err := coll.InsertOne(ctx, bson.M{"_id": "priya", "age": 22})
+	if err != nil || id != "priya" {
+		t.Fatalf("InsertOne = %v, %v", id, err)
+	}
+
+	ids, err := coll.InsertMany(ctx, []interface{}{bson.M{"_id": int64(7)}, bson.M{"name": "Shekhar"}})
+	if err != nil {
+		t.Fatalf("InsertMany: %v", err)
+	}
+	if ids[0] != int64(7) {
+		t.Errorf("ids[0] = %#v, want int64(7)", ids[0])
+	}
+	if _, ok := ids[1].(primitive.ObjectID); !ok {
+		t.Errorf("ids[1] = %#v, want a generated ObjectID", ids[1])
+	}
+}
+
+func TestFindOneAndUpdateReturnDocument(t *testing.T) {
+	coll := mongodbtest.NewClient().Database("testdb").Collection("counters")
+	ctx := context.TODO()
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var counter struct {
+		Seq int `bson:"seq"`
+	}
+	for want := 1; want <= 2; want++ {
+		if err := coll.FindOneAndUpdate(ctx, bson.M{"_id": "orders"}, bson.M{"$inc": bson.M{"seq": 1}}, &counter, opts); err != nil {
+			t.Fatalf("FindOneAndUpdate: %v", err)
+		}
+		if counter.Seq != want {
+			t.Errorf("seq = %d, want %d", counter.Seq, want)
+		}
+	}
+
+	var before User
+	err := coll.FindOneAndReplace(ctx, bson.M{"_id": "orders"}, bson.M{"name": "replaced"}, &before)
+	if err != nil {
+		t.Fatalf("FindOneAndReplace: %v", err)
+	}
+	if before.Id != "orders" || before.Name != "" {
+		t.Errorf("got %+v, want the document before replacement", before)
+	}
+}
+
+func TestFindIterator(t *testing.T) {
+	coll := seed(t)
+	ctx := context.TODO()
+
+	it, err := coll.FindIterator(ctx, bson.M{}, options.Find().SetSort(bson.M{"age": 1}).SetBatchSize(2))
+	if err != nil {
+		t.Fatalf("FindIterator: %v", err)
+	}
+
+	var got []string
+	var batches []int
+	for it.Next(ctx) {
+		var u User
+		if err := it.Decode(&u); err != nil {
+			t.Fatalf("Decode: %v", err)
+		}
+		got = append(got, u.Name)
+		batches = append(batches, it.RemainingBatchLength())
+	}
+	if err := it.Close(ctx); err != nil || it.Err() != nil {
+		t.Fatalf("Close = %v, Err = %v", err, it.Err())
+	}
+	if want := []string{"Priya", "Shekhar", "Subrato"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if want := []int{1, 0, 0}; !reflect.DeepEqual(batches, want) {
+		t.Errorf("remaining batch lengths = %v, want %v", batches, want)
+	}
+
+	errStop := errors.New("stop")
+	var seen int
+	err = coll.FindEach(ctx, bson.M{}, func(decode func(v interface{}) error) error {
+		seen++
+		return errStop
+	})
+	if err != errStop || seen != 1 {
+		t.Errorf("FindEach = %v after %d documents, want %v after 1", err, seen, errStop)
+	}
+}
+
+func TestWithTransactionRollsBack(t *testing.T) {
+	coll := seed(t)
+	ctx := context.TODO()
+	errAbort := errors.New("abort")
+
+	err := coll.Database().Client().WithTransaction(ctx, func(tx mongodb.TxContext) error {
+		users := tx.Database("testdb").Collection("users")
+		if _, err := users.DeleteMany(tx, bson.M{}); err != nil {
+			return err
+		}
+		if _, err := tx.Database("other").Collection("log").InsertOne(tx, bson.M{"msg": "deleted"}); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if err != errAbort {
+		t.Fatalf("WithTransaction = %v, want %v", err, errAbort)
+	}
+
+	if n, _ := coll.EstimatedDocumentCount(ctx); n != 3 {
+		t.Errorf("count = %d after rollback, want 3", n)
+	}
+	if dbs, _ := coll.Database().Client().ListDatabaseNames(ctx, bson.M{}); len(dbs) != 1 {
+		t.Errorf("databases = %v after rollback, want only testdb", dbs)
+	}
+}
+
+func TestUniqueIndex(t *testing.T) {
+	coll := seed(t)
+	ctx := context.TODO()
+
+	name, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil || name != "name_1" {
+		t.Fatalf("CreateOne = %q, %v", name, err)
+	}
+
+	if _, err := coll.InsertOne(ctx, User{Name: "Priya"}); !mongo.IsDuplicateKeyError(err) {
+		t.Errorf("InsertOne err = %v, want a duplicate key error", err)
+	}
+	if _, err := coll.UpdateOne(ctx, bson.M{"name": "Shekhar"}, bson.M{"$set": bson.M{"name": "Subrato"}}); !mongo.IsDuplicateKeyError(err) {
+		t.Errorf("UpdateOne err = %v, want a duplicate key error", err)
+	}
+
+	specs, err := coll.Indexes().ListSpecifications(ctx)
+	if err != nil || len(specs) != 2 || specs[1].Name != "name_1" {
+		t.Fatalf("ListSpecifications = %v, %v", specs, err)
+	}
+
+	if _, err := coll.Indexes().DropOne(ctx, "name_1"); err != nil {
+		t.Fatalf("DropOne: %v", err)
+	}
+	if _, err := coll.InsertOne(ctx, User{Name: "Priya"}); err != nil {
+		t.Errorf("InsertOne after DropOne: %v", err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	client := mongodbtest.NewClient()
+	coll := client.Database("testdb").Collection("users")
+	ctx := context.TODO()
+
+	cs, err := coll.Watch(ctx, mongo.Pipeline{{{Key: "$match", Value: bson.M{"operationType": bson.M{"$ne": "delete"}}}}},
+		options.ChangeStream().SetFullDocument(options.UpdateLookup))
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	defer cs.Close(ctx)
+
+	if cs.TryNext(ctx) {
+		t.Fatal("TryNext reported an event on an idle collection")
+	}
+
+	if _, err := coll.InsertOne(ctx, User{Id: "u1", Name: "Priya", Age: 22}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Database("testdb").Collection("other").InsertOne(ctx, User{Id: "o1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := coll.UpdateByID(ctx, "u1", bson.M{"$set": bson.M{"age": 23}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := coll.DeleteOne(ctx, bson.M{"_id": "u1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	type change struct {
+		OperationType     string `bson:"operationType"`
+		FullDocument      User   `bson:"fullDocument"`
+		UpdateDescription struct {
+			UpdatedFields bson.M `bson:"updatedFields"`
+		} `bson:"updateDescription"`
+	}
+
+	var got []change
+	for len(got) < 2 && cs.Next(ctx) {
+		var c change
+		if err := cs.Decode(&c); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, c)
+	}
+	if err := cs.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].OperationType != "insert" || got[1].OperationType != "update" {
+		t.Fatalf("events = %+v", got)
+	}
+	if got[1].FullDocument.Age != 23 || got[1].UpdateDescription.UpdatedFields["age"] != int32(23) {
+		t.Errorf("update event = %+v", got[1])
+	}
+
+	resumed, err := coll.Watch(ctx, nil, options.ChangeStream().SetResumeAfter(cs.ResumeToken()))
+	if err != nil {
+		t.Fatalf("Watch with ResumeAfter: %v", err)
+	}
+	var c change
+	if !resumed.TryNext(ctx) || resumed.Decode(&c) != nil || c.OperationType != "delete" {
+		t.Errorf("resumed stream = %+v, %v", c, resumed.Err())
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if resumed.Next(cancelled) || !errors.Is(resumed.Err(), context.Canceled) {
+		t.Errorf("Next on a cancelled context: err = %v", resumed.Err())
+	}
+}
+
+func TestNonMapFilters(t *testing.T) {
+	coll := seed(t)
+	ctx := context.TODO()
+
+	n, err := coll.CountDocuments(ctx, bson.D{{Key: "age", Value: bson.D{{Key: "$gt", Value: 22}}}})
+	if err != nil || n != 2 {
+		t.Errorf("CountDocuments with bson.D = %d, %v", n, err)
+	}
+
+	names, err := coll.Distinct(ctx, "name", struct {
+		Age int `bson:"age"`
+	}{Age: 22})
+	if err != nil || !reflect.DeepEqual(names, []interface{}{"Priya"}) {
+		t.Errorf("Distinct with struct filter = %v, %v", names, err)
+	}
+
+	var u User
+	if err := coll.FindOneAndDelete(ctx, nil, &u, options.FindOneAndDelete().SetSort(bson.D{{Key: "age", Value: -1}})); err != nil || u.Name != "Subrato" {
+		t.Errorf("FindOneAndDelete with nil filter = %+v, %v", u, err)
+	}
+}
@@ -0,0 +1,152 @@
+package mongodbtest
+
+import (
+	"context"
+	"sort"
+
+	"github.com/subratohld/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+func newDB(c *client, name string, opts ...*options.DatabaseOptions) mongodb.Database {
+	return &database{
+		client: c,
+		name:   name,
+		opts:   options.MergeDatabaseOptions(opts...),
+	}
+}
+
+type database struct {
+	client *client
+	name   string
+	opts   *options.DatabaseOptions
+}
+
+func (db *database) Name() string {
+	return db.name
+}
+
+func (db *database) Client() mongodb.Client {
+	return db.client
+}
+
+func (db *database) Collection(name string, opts ...*options.CollectionOptions) mongodb.Collection {
+	return newCollection(db, name)
+}
+
+// CollectionWith applies the behaviours configured by opts; driver
+// collection options are ignored.
+func (db *database) CollectionWith(name string, opts ...mongodb.CollectionOption) mongodb.Collection {
+	return mongodb.WrapCollection(newCollection(db, name), opts...)
+}
+
+func (db *database) CreateCollection(ctx context.Context, name string, opts ...*options.CreateCollectionOptions) error {
+	db.client.mu.Lock()
+	defer db.client.mu.Unlock()
+
+	if db.client.store(db.name, name, false) != nil {
+		return mongo.CommandError{Code: 48, Name: "NamespaceExists", Message: "Collection already exists. NS: " + db.name + "." + name}
+	}
+	db.client.store(db.name, name, true)
+	return nil
+}
+
+func (db *database) ListCollections(ctx context.Context, filter interface{}, opts ...*options.ListCollectionsOptions) (*mongo.Cursor, error) {
+	return nil, ErrNotSupported
+}
+
+func (db *database) ListCollectionNames(ctx context.Context, filter interface{}, opts ...*options.ListCollectionsOptions) ([]string, error) {
+	specs, err := db.ListCollectionSpecifications(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(specs))
+	for i, spec := range specs {
+		names[i] = spec.Name
+	}
+	return names, nil
+}
+
+func (db *database) ListCollectionSpecifications(ctx context.Context, filter interface{}, opts ...*options.ListCollectionsOptions) ([]*mongo.CollectionSpecification, error) {
+	f, err := toDoc(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	db.client.mu.Lock()
+	defer db.client.mu.Unlock()
+
+	d, ok := db.client.dbs[db.name]
+	if !ok {
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(d.colls))
+	for name := range d.colls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var specs []*mongo.CollectionSpecification
+	for _, name := range names {
+		ok, err := matches(bson.D{{Key: "name", Value: name}, {Key: "type", Value: "collection"}}, f)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			specs = append(specs, &mongo.CollectionSpecification{Name: name, Type: "collection"})
+		}
+	}
+	return specs, nil
+}
+
+func (db *database) CreateView(ctx context.Context, name, viewOn string, pipeline interface{}, opts ...*options.CreateViewOptions) error {
+	return ErrNotSupported
+}
+
+func (db *database) Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (*mongo.Cursor, error) {
+	return nil, ErrNotSupported
+}
+
+func (db *database) Drop(ctx context.Context) error {
+	db.client.mu.Lock()
+	defer db.client.mu.Unlock()
+
+	delete(db.client.dbs, db.name)
+	return nil
+}
+
+// RunCommand is not supported. The returned result holds no document, so
+// decoding it reports mongo.ErrNoDocuments.
+func (db *database) RunCommand(ctx context.Context, runCmd interface{}, opts ...*options.RunCmdOptions) *mongo.SingleResult {
+	return &mongo.SingleResult{}
+}
+
+func (db *database) RunCommandCursor(ctx context.Context, runCmd interface{}, opts ...*options.RunCmdOptions) (*mongo.Cursor, error) {
+	return nil, ErrNotSupported
+}
+
+func (db *database) ReadConcern() *readconcern.ReadConcern {
+	return db.opts.ReadConcern
+}
+
+func (db *database) WriteConcern() *writeconcern.WriteConcern {
+	return db.opts.WriteConcern
+}
+
+func (db *database) ReadPreference() *readpref.ReadPref {
+	if db.opts.ReadPreference == nil {
+		return readpref.Primary()
+	}
+	return db.opts.ReadPreference
+}
+
+func (db *database) Watch(ctx context.Context, pipeline interface{}, opts ...*options.ChangeStreamOptions) (mongodb.ChangeStream, error) {
+	return db.client.watch(db.name, "", pipeline, opts...)
+}
@@ -0,0 +1,214 @@
+package mongodbtest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/subratohld/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const idIndexName = "_id_"
+
+type index struct {
+	name   string
+	keys   bson.D
+	unique bool
+	sparse bool
+}
+
+func (ix index) spec(ns string) bson.D {
+	spec := bson.D{{Key: "v", Value: int32(2)}, {Key: "key", Value: ix.keys}, {Key: "name", Value: ix.name}, {Key: "ns", Value: ns}}
+	if ix.unique {
+		spec = append(spec, bson.E{Key: "unique", Value: true})
+	}
+	if ix.sparse {
+		spec = append(spec, bson.E{Key: "sparse", Value: true})
+	}
+	return spec
+}
+
+// key returns the values doc holds for the indexed fields and whether the
+// document is covered by the index.
+func (ix index) key(doc bson.D) ([]interface{}, bool) {
+	values := make([]interface{}, len(ix.keys))
+	var found bool
+	for i, k := range ix.keys {
+		if v := lookupPath(doc, k.Key); len(v) > 0 {
+			values[i] = v[0]
+			found = true
+		}
+	}
+	return values, found || !ix.sparse
+}
+
+// checkUnique reports a duplicate key error when doc collides with a stored
+// document other than the one at position skip under a unique index. The
+// caller must hold the client lock.
+func (coll *collection) checkUnique(s *collStore, doc bson.D, skip int) error {
+	for _, ix := range s.indexes {
+		if !ix.unique {
+			continue
+		}
+		key, ok := ix.key(doc)
+		if !ok {
+			continue
+		}
+		for i, other := range s.docs {
+			if i == skip {
+				continue
+			}
+			if otherKey, ok := ix.key(other); ok && equalValues(bson.A(key), bson.A(otherKey)) {
+				dup := make(bson.D, len(key))
+				for j, k := range ix.keys {
+					dup[j] = bson.E{Key: k.Key, Value: key[j]}
+				}
+				return coll.duplicateKeyError(ix.name, dup)
+			}
+		}
+	}
+	return nil
+}
+
+func newIndexView(coll *collection) mongodb.IndexView {
+	return &indexView{coll: coll}
+}
+
+// indexView keeps index definitions in memory. Unique indexes are enforced
+// on writes; other index options are recorded but have no effect.
+type indexView struct {
+	coll *collection
+}
+
+func (iv *indexView) specs() []bson.D {
+	defer iv.coll.lock()()
+
+	ns := iv.coll.db.name + "." + iv.coll.name
+	specs := []bson.D{index{name: idIndexName, keys: bson.D{{Key: "_id", Value: int32(1)}}}.spec(ns)}
+	for _, ix := range iv.coll.store(false).indexes {
+		specs = append(specs, ix.spec(ns))
+	}
+	return specs
+}
+
+func (iv *indexView) List(ctx context.Context, opts ...*options.ListIndexesOptions) (mongodb.Iterator, error) {
+	return newIterator(iv.specs(), options.MergeListIndexesOptions(opts...).BatchSize), nil
+}
+
+func (iv *indexView) ListSpecifications(ctx context.Context, opts ...*options.ListIndexesOptions) ([]*mongo.IndexSpecification, error) {
+	specs := iv.specs()
+	out := make([]*mongo.IndexSpecification, len(specs))
+	for i, spec := range specs {
+		out[i] = &mongo.IndexSpecification{}
+		if err := decode(spec, out[i]); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (iv *indexView) CreateOne(ctx context.Context, model mongo.IndexModel, opts ...*options.CreateIndexesOptions) (string, error) {
+	names, err := iv.CreateMany(ctx, []mongo.IndexModel{model}, opts...)
+	if err != nil {
+		return "", err
+	}
+	return names[0], nil
+}
+
+func (iv *indexView) CreateMany(ctx context.Context, models []mongo.IndexModel, opts ...*options.CreateIndexesOptions) ([]string, error) {
+	defer iv.coll.lock()()
+
+	s := iv.coll.store(true)
+	names := make([]string, len(models))
+	for i, model := range models {
+		ix, err := newIndex(model)
+		if err != nil {
+			return nil, err
+		}
+		names[i] = ix.name
+
+		if existing := findIndex(s, ix.name); existing >= 0 || ix.name == idIndexName {
+			continue
+		}
+
+		s.indexes = append(s.indexes, ix)
+		for j, doc := range s.docs {
+			if err := iv.coll.checkUnique(s, doc, j); err != nil {
+				s.indexes = s.indexes[:len(s.indexes)-1]
+				return nil, err
+			}
+		}
+	}
+	return names, nil
+}
+
+func (iv *indexView) DropOne(ctx context.Context, name string, opts ...*options.DropIndexesOptions) (bson.Raw, error) {
+	if name == "*" {
+		return nil, mongo.ErrMultipleIndexDrop
+	}
+	if name == idIndexName {
+		return nil, mongo.CommandError{Code: 72, Name: "InvalidOptions", Message: "cannot drop _id index"}
+	}
+
+	defer iv.coll.lock()()
+
+	s := iv.coll.store(false)
+	i := findIndex(s, name)
+	if i < 0 {
+		return nil, mongo.CommandError{Code: 27, Name: "IndexNotFound", Message: "index not found with name [" + name + "]"}
+	}
+	was := len(s.indexes) + 1
+	s.indexes = append(s.indexes[:i:i], s.indexes[i+1:]...)
+	return bson.Marshal(bson.D{{Key: "nIndexesWas", Value: int32(was)}, {Key: "ok", Value: 1.0}})
+}
+
+func (iv *indexView) DropAll(ctx context.Context, opts ...*options.DropIndexesOptions) (bson.Raw, error) {
+	defer iv.coll.lock()()
+
+	s := iv.coll.store(false)
+	was := len(s.indexes) + 1
+	s.indexes = nil
+	return bson.Marshal(bson.D{{Key: "nIndexesWas", Value: int32(was)}, {Key: "ok", Value: 1.0}})
+}
+
+func newIndex(model mongo.IndexModel) (index, error) {
+	if model.Keys == nil {
+		return index{}, fmt.Errorf("mongodbtest: index keys cannot be nil")
+	}
+	keys, err := toDoc(model.Keys)
+	if err != nil {
+		return index{}, err
+	}
+	if len(keys) == 0 {
+		return index{}, fmt.Errorf("mongodbtest: index keys cannot be empty")
+	}
+
+	ix := index{keys: keys}
+	if o := model.Options; o != nil {
+		if o.Name != nil {
+			ix.name = *o.Name
+		}
+		ix.unique = o.Unique != nil && *o.Unique
+		ix.sparse = o.Sparse != nil && *o.Sparse
+	}
+	if ix.name == "" {
+		parts := make([]string, 0, 2*len(keys))
+		for _, k := range keys {
+			parts = append(parts, k.Key, fmt.Sprint(k.Value))
+		}
+		ix.name = strings.Join(parts, "_")
+	}
+	return ix, nil
+}
+
+func findIndex(s *collStore, name string) int {
+	for i, ix := range s.indexes {
+		if ix.name == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,72 @@
+package mongodbtest
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var errNoCurrent = errors.New("mongodbtest: Decode called without a current document")
+
+// iterator walks an in-memory result set, handing out documents in batches
+// to mimic the batch accounting of a driver cursor.
+type iterator struct {
+	docs      []bson.D
+	pos       int
+	batchSize int
+	batchEnd  int
+	current   bson.D
+	closed    bool
+	err       error
+}
+
+func newIterator(docs []bson.D, batchSize *int32) *iterator {
+	it := &iterator{docs: docs, batchSize: len(docs)}
+	if batchSize != nil && *batchSize > 0 {
+		it.batchSize = int(*batchSize)
+	}
+	return it
+}
+
+func (it *iterator) Next(ctx context.Context) bool {
+	it.current = nil
+	if it.closed || it.pos >= len(it.docs) {
+		return false
+	}
+	if err := ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+
+	if it.pos >= it.batchEnd {
+		it.batchEnd = it.pos + it.batchSize
+	}
+	it.current = it.docs[it.pos]
+	it.pos++
+	return true
+}
+
+func (it *iterator) Decode(v interface{}) error {
+	if it.current == nil {
+		return errNoCurrent
+	}
+	return decode(it.current, v)
+}
+
+func (it *iterator) Err() error {
+	return it.err
+}
+
+func (it *iterator) Close(ctx context.Context) error {
+	it.closed = true
+	it.docs = nil
+	return nil
+}
+
+func (it *iterator) RemainingBatchLength() int {
+	if it.batchEnd > len(it.docs) {
+		return len(it.docs) - it.pos
+	}
+	return it.batchEnd - it.pos
+}
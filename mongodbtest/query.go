@@ -0,0 +1,428 @@
+package mongodbtest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/subratohld/mongodb/internal/bsonutil"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func matches(doc bson.D, filter bson.D) (bool, error) {
+	for _, e := range filter {
+		ok, err := matchElem(doc, e)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchElem(doc bson.D, e bson.E) (bool, error) {
+	switch e.Key {
+	case "$and", "$or", "$nor":
+		return matchLogical(doc, e)
+	}
+	if strings.HasPrefix(e.Key, "$") {
+		return false, fmt.Errorf("mongodbtest: unsupported query operator %q", e.Key)
+	}
+
+	values := lookupPath(doc, e.Key)
+	if ops, ok := operatorDoc(e.Value); ok {
+		for _, op := range ops {
+			ok, err := matchOperator(values, op, ops)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+	if re, ok := e.Value.(primitive.Regex); ok {
+		return matchRegex(values, re.Pattern, re.Options)
+	}
+	return matchEq(values, e.Value), nil
+}
+
+func matchLogical(doc bson.D, e bson.E) (bool, error) {
+	clauses, ok := e.Value.(primitive.A)
+	if !ok || len(clauses) == 0 {
+		return false, fmt.Errorf("mongodbtest: %s must be a nonempty array", e.Key)
+	}
+
+	for _, clause := range clauses {
+		sub, ok := clause.(primitive.D)
+		if !ok {
+			return false, fmt.Errorf("mongodbtest: %s entries must be documents", e.Key)
+		}
+		ok, err := matches(doc, sub)
+		if err != nil {
+			return false, err
+		}
+		switch {
+		case e.Key == "$and" && !ok:
+			return false, nil
+		case e.Key == "$or" && ok:
+			return true, nil
+		case e.Key == "$nor" && ok:
+			return false, nil
+		}
+	}
+	return e.Key != "$or", nil
+}
+
+// operatorDoc reports whether v is a document made only of $-operators.
+func operatorDoc(v interface{}) (bson.D, bool) {
+	d, ok := v.(primitive.D)
+	if !ok || len(d) == 0 {
+		return nil, false
+	}
+	for _, e := range d {
+		if !strings.HasPrefix(e.Key, "$") {
+			return nil, false
+		}
+	}
+	return d, true
+}
+
+// candidates expands array values so that a scalar condition can match any of
+// their elements as well as the array itself.
+func candidates(values []interface{}) []interface{} {
+	var out []interface{}
+	for _, v := range values {
+		out = append(out, v)
+		if arr, ok := v.(primitive.A); ok {
+			out = append(out, arr...)
+		}
+	}
+	return out
+}
+
+func matchEq(values []interface{}, target interface{}) bool {
+	if len(values) == 0 {
+		return typeRank(target) == typeRank(nil)
+	}
+	for _, v := range candidates(values) {
+		if equalValues(v, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchIn(values []interface{}, list interface{}) (bool, error) {
+	arr, ok := list.(primitive.A)
+	if !ok {
+		return false, fmt.Errorf("mongodbtest: $in/$nin needs an array")
+	}
+	for _, target := range arr {
+		if re, ok := target.(primitive.Regex); ok {
+			ok, err := matchRegex(values, re.Pattern, re.Options)
+			if err != nil || ok {
+				return ok, err
+			}
+			continue
+		}
+		if matchEq(values, target) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func matchCompare(values []interface{}, target interface{}, accept func(int) bool) bool {
+	for _, v := range candidates(values) {
+		if typeRank(v) == typeRank(target) && accept(compareValues(v, target)) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchRegex(values []interface{}, pattern, opts string) (bool, error) {
+	re, err := compileRegex(pattern, opts)
+	if err != nil {
+		return false, err
+	}
+	for _, v := range candidates(values) {
+		if s, ok := v.(string); ok && re.MatchString(s) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func matchOperator(values []interface{}, op bson.E, siblings bson.D) (bool, error) {
+	switch op.Key {
+	case "$eq":
+		return matchEq(values, op.Value), nil
+	case "$ne":
+		return !matchEq(values, op.Value), nil
+	case "$gt":
+		return matchCompare(values, op.Value, func(c int) bool { return c > 0 }), nil
+	case "$gte":
+		return matchCompare(values, op.Value, func(c int) bool { return c >= 0 }), nil
+	case "$lt":
+		return matchCompare(values, op.Value, func(c int) bool { return c < 0 }), nil
+	case "$lte":
+		return matchCompare(values, op.Value, func(c int) bool { return c <= 0 }), nil
+	case "$in":
+		return matchIn(values, op.Value)
+	case "$nin":
+		ok, err := matchIn(values, op.Value)
+		return !ok, err
+	case "$exists":
+		return truthy(op.Value) == (len(values) > 0), nil
+	case "$regex":
+		var opts string
+		if o, ok := bsonutil.Lookup(siblings, "$options"); ok {
+			opts, _ = o.(string)
+		}
+		switch p := op.Value.(type) {
+		case string:
+			return matchRegex(values, p, opts)
+		case primitive.Regex:
+			return matchRegex(values, p.Pattern, p.Options+opts)
+		}
+		return false, fmt.Errorf("mongodbtest: $regex has to be a string")
+	case "$options":
+		return true, nil
+	case "$not":
+		var ok bool
+		var err error
+		switch t := op.Value.(type) {
+		case primitive.Regex:
+			ok, err = matchRegex(values, t.Pattern, t.Options)
+		case primitive.D:
+			ok, err = matchAllOps(values, t)
+		default:
+			return false, fmt.Errorf("mongodbtest: $not needs a regex or a document")
+		}
+		return !ok, err
+	case "$size":
+		n, ok := intValue(op.Value)
+		if !ok {
+			return false, fmt.Errorf("mongodbtest: $size needs an integer")
+		}
+		for _, v := range values {
+			if arr, ok := v.(primitive.A); ok && int64(len(arr)) == n {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "$all":
+		arr, ok := op.Value.(primitive.A)
+		if !ok {
+			return false, fmt.Errorf("mongodbtest: $all needs an array")
+		}
+		if len(arr) == 0 {
+			return false, nil
+		}
+		for _, target := range arr {
+			if !matchEq(values, target) {
+				return false, nil
+			}
+		}
+		return true, nil
+	case "$elemMatch":
+		cond, ok := op.Value.(primitive.D)
+		if !ok {
+			return false, fmt.Errorf("mongodbtest: $elemMatch needs an object")
+		}
+		return matchElemMatch(values, cond)
+	}
+	return false, fmt.Errorf("mongodbtest: unsupported query operator %q", op.Key)
+}
+
+func matchAllOps(values []interface{}, ops bson.D) (bool, error) {
+	for _, op := range ops {
+		ok, err := matchOperator(values, op, ops)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchElemMatch(values []interface{}, cond bson.D) (bool, error) {
+	_, isOps := operatorDoc(cond)
+	for _, v := range values {
+		arr, ok := v.(primitive.A)
+		if !ok {
+			continue
+		}
+		for _, elem := range arr {
+			var ok bool
+			var err error
+			if isOps {
+				ok, err = matchAllOps([]interface{}{elem}, cond)
+			} else if d, isDoc := elem.(primitive.D); isDoc {
+				ok, err = matches(d, cond)
+			}
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+	}
+	return false, nil
+}
+
+func firstOrNil(values []interface{}) interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	return values[0]
+}
+
+func sortDocs(docs []bson.D, spec bson.D) error {
+	less, err := sortLess(spec)
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(docs, func(i, j int) bool {
+		return less(docs[i], docs[j])
+	})
+	return nil
+}
+
+// sortLess builds an ordering function from a sort specification such as
+// {age: -1, name: 1}.
+func sortLess(spec bson.D) (func(a, b bson.D) bool, error) {
+	dirs := make([]int, len(spec))
+	for i, e := range spec {
+		if !isNumber(e.Value) {
+			return nil, fmt.Errorf("mongodbtest: unsupported sort specification for %q", e.Key)
+		}
+		dirs[i] = 1
+		if floatValue(e.Value) < 0 {
+			dirs[i] = -1
+		}
+	}
+
+	return func(a, b bson.D) bool {
+		for k, e := range spec {
+			x := firstOrNil(lookupPath(a, e.Key))
+			y := firstOrNil(lookupPath(b, e.Key))
+			if c := compareValues(x, y); c != 0 {
+				return c*dirs[k] < 0
+			}
+		}
+		return false
+	}, nil
+}
+
+type projectionTree map[string]projectionTree
+
+func (t projectionTree) add(path string) {
+	parts := strings.SplitN(path, ".", 2)
+	if len(parts) == 1 {
+		t[parts[0]] = nil
+		return
+	}
+	sub, ok := t[parts[0]]
+	if ok && sub == nil {
+		return
+	}
+	if sub == nil {
+		sub = projectionTree{}
+		t[parts[0]] = sub
+	}
+	sub.add(parts[1])
+}
+
+// project applies an inclusion or exclusion projection. Projection operators
+// such as $slice and $elemMatch are not supported.
+func project(doc bson.D, spec bson.D) (bson.D, error) {
+	if len(spec) == 0 {
+		return doc, nil
+	}
+
+	included, excluded := projectionTree{}, projectionTree{}
+	keepID := true
+	for _, e := range spec {
+		if _, ok := e.Value.(primitive.D); ok {
+			return nil, fmt.Errorf("mongodbtest: unsupported projection for %q", e.Key)
+		}
+		switch {
+		case e.Key == "_id":
+			keepID = truthy(e.Value)
+		case truthy(e.Value):
+			included.add(e.Key)
+		default:
+			excluded.add(e.Key)
+		}
+	}
+	if len(included) > 0 && len(excluded) > 0 {
+		return nil, fmt.Errorf("mongodbtest: cannot mix inclusion and exclusion in a projection")
+	}
+
+	if len(included) > 0 {
+		if keepID {
+			included["_id"] = nil
+		}
+		return includeFields(doc, included), nil
+	}
+	if !keepID {
+		excluded["_id"] = nil
+	}
+	return excludeFields(doc, excluded), nil
+}
+
+func includeFields(doc bson.D, tree projectionTree) bson.D {
+	out := bson.D{}
+	for _, e := range doc {
+		sub, ok := tree[e.Key]
+		if !ok {
+			continue
+		}
+		if sub == nil {
+			out = append(out, e)
+			continue
+		}
+		switch t := e.Value.(type) {
+		case primitive.D:
+			out = append(out, bson.E{Key: e.Key, Value: includeFields(t, sub)})
+		case primitive.A:
+			arr := primitive.A{}
+			for _, elem := range t {
+				if d, ok := elem.(primitive.D); ok {
+					arr = append(arr, includeFields(d, sub))
+				}
+			}
+			out = append(out, bson.E{Key: e.Key, Value: arr})
+		}
+	}
+	return out
+}
+
+func excludeFields(doc bson.D, tree projectionTree) bson.D {
+	out := bson.D{}
+	for _, e := range doc {
+		sub, ok := tree[e.Key]
+		if !ok {
+			out = append(out, e)
+			continue
+		}
+		if sub == nil {
+			continue
+		}
+		switch t := e.Value.(type) {
+		case primitive.D:
+			out = append(out, bson.E{Key: e.Key, Value: excludeFields(t, sub)})
+		case primitive.A:
+			arr := make(primitive.A, len(t))
+			for i, elem := range t {
+				if d, ok := elem.(primitive.D); ok {
+					elem = excludeFields(d, sub)
+				}
+				arr[i] = elem
+			}
+			out = append(out, bson.E{Key: e.Key, Value: arr})
+		default:
+			out = append(out, e)
+		}
+	}
+	return out
+}
@@ -0,0 +1,60 @@
+package mongodbtest
+
+import (
+	"context"
+
+	"github.com/subratohld/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type txContext struct {
+	context.Context
+	client *client
+}
+
+// Session returns nil: the in-memory client has no driver sessions.
+func (tx *txContext) Session() mongo.Session {
+	return nil
+}
+
+func (tx *txContext) Database(name string, opts ...*options.DatabaseOptions) mongodb.Database {
+	return newDB(tx.client, name, opts...)
+}
+
+// WithTransaction runs fn once. When fn fails, every database of the client
+// is restored to its state before the call; concurrent writes are not
+// isolated from the transaction, and change events recorded by fn are not
+// withdrawn. Transaction options are ignored.
+func (c *client) WithTransaction(ctx context.Context, fn func(tx mongodb.TxContext) error, opts ...mongodb.TxOption) error {
+	c.mu.Lock()
+	snapshot := c.snapshot()
+	c.mu.Unlock()
+
+	if err := fn(&txContext{Context: ctx, client: c}); err != nil {
+		c.mu.Lock()
+		c.dbs = snapshot
+		c.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// snapshot copies the store. Stored documents are never modified in place,
+// so copying the document and index slices is enough. The caller must hold
+// c.mu.
+func (c *client) snapshot() map[string]*dbStore {
+	dbs := make(map[string]*dbStore, len(c.dbs))
+	for name, db := range c.dbs {
+		colls := make(map[string]*collStore, len(db.colls))
+		for cname, coll := range db.colls {
+			colls[cname] = &collStore{
+				docs:    append([]bson.D(nil), coll.docs...),
+				indexes: append([]index(nil), coll.indexes...),
+			}
+		}
+		dbs[name] = &dbStore{colls: colls}
+	}
+	return dbs
+}
@@ -0,0 +1,164 @@
+package mongodbtest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/subratohld/mongodb/internal/bsonutil"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func validateUpdate(update bson.D) error {
+	if len(update) == 0 {
+		return errors.New("mongodbtest: update document must not be empty")
+	}
+	for _, e := range update {
+		if !strings.HasPrefix(e.Key, "$") {
+			return errors.New("mongodbtest: update document must contain key beginning with '$'")
+		}
+	}
+	return nil
+}
+
+func validateReplacement(replacement bson.D) error {
+	for _, e := range replacement {
+		if strings.HasPrefix(e.Key, "$") {
+			return errors.New("mongodbtest: replacement document cannot contain keys beginning with '$'")
+		}
+	}
+	return nil
+}
+
+// applyUpdate returns a copy of doc with the update operators applied.
+// Supported operators are $set, $setOnInsert, $unset, $inc and $push.
+func applyUpdate(doc bson.D, update bson.D, inserting bool) (bson.D, error) {
+	doc = cloneDoc(doc)
+	for _, op := range update {
+		fields, ok := op.Value.(primitive.D)
+		if !ok {
+			return nil, fmt.Errorf("mongodbtest: modifier %s needs a document", op.Key)
+		}
+
+		for _, f := range fields {
+			if f.Key == "_id" && op.Key != "$setOnInsert" {
+				if cur, _ := bsonutil.Lookup(doc, "_id"); op.Key != "$set" || !equalValues(cur, f.Value) {
+					return nil, errors.New("mongodbtest: performing an update on the path '_id' would modify the immutable field '_id'")
+				}
+			}
+
+			parts := strings.Split(f.Key, ".")
+			var err error
+			switch op.Key {
+			case "$set":
+				doc, err = setPath(doc, parts, f.Value)
+			case "$setOnInsert":
+				if inserting {
+					doc, err = setPath(doc, parts, f.Value)
+				}
+			case "$unset":
+				doc = unsetPath(doc, parts)
+			case "$inc":
+				doc, err = applyInc(doc, parts, f)
+			case "$push":
+				doc, err = applyPush(doc, parts, f)
+			default:
+				return nil, fmt.Errorf("mongodbtest: unsupported update operator %q", op.Key)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return doc, nil
+}
+
+func applyInc(doc bson.D, parts []string, f bson.E) (bson.D, error) {
+	if !isNumber(f.Value) {
+		return nil, fmt.Errorf("mongodbtest: cannot increment with non-numeric argument for %q", f.Key)
+	}
+
+	cur, ok := getPath(doc, parts)
+	if !ok {
+		return setPath(doc, parts, f.Value)
+	}
+	if !isNumber(cur) {
+		return nil, fmt.Errorf("mongodbtest: cannot apply $inc to a value of non-numeric type at %q", f.Key)
+	}
+	return setPath(doc, parts, addNumbers(cur, f.Value))
+}
+
+func applyPush(doc bson.D, parts []string, f bson.E) (bson.D, error) {
+	items := primitive.A{f.Value}
+	if mods, ok := f.Value.(primitive.D); ok {
+		if each, ok := bsonutil.Lookup(mods, "$each"); ok {
+			arr, ok := each.(primitive.A)
+			if !ok {
+				return nil, fmt.Errorf("mongodbtest: $each for %q must be an array", f.Key)
+			}
+			items = arr
+		}
+	}
+
+	cur, ok := getPath(doc, parts)
+	if !ok {
+		return setPath(doc, parts, append(primitive.A{}, items...))
+	}
+	arr, isArr := cur.(primitive.A)
+	if !isArr {
+		return nil, fmt.Errorf("mongodbtest: the field %q must be an array", f.Key)
+	}
+	return setPath(doc, parts, append(arr, items...))
+}
+
+// upsertSeed builds the document that an upsert starts from: the equality
+// conditions of the filter.
+func upsertSeed(filter bson.D) (bson.D, error) {
+	seed := bson.D{}
+	for _, e := range filter {
+		if strings.HasPrefix(e.Key, "$") {
+			if e.Key != "$and" {
+				continue
+			}
+			clauses, _ := e.Value.(primitive.A)
+			for _, clause := range clauses {
+				sub, ok := clause.(primitive.D)
+				if !ok {
+					continue
+				}
+				var err error
+				if seed, err = mergeSeed(seed, sub); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		var err error
+		if seed, err = mergeSeed(seed, bson.D{e}); err != nil {
+			return nil, err
+		}
+	}
+	return seed, nil
+}
+
+func mergeSeed(seed bson.D, filter bson.D) (bson.D, error) {
+	for _, e := range filter {
+		if strings.HasPrefix(e.Key, "$") {
+			continue
+		}
+		value := e.Value
+		if ops, ok := operatorDoc(value); ok {
+			eq, ok := bsonutil.Lookup(ops, "$eq")
+			if !ok {
+				continue
+			}
+			value = eq
+		}
+		var err error
+		if seed, err = setPath(seed, strings.Split(e.Key, "."), value); err != nil {
+			return nil, err
+		}
+	}
+	return seed, nil
+}
@@ -0,0 +1,422 @@
+package mongodbtest
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/subratohld/mongodb/internal/bsonutil"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// toDoc decodes v the way the driver would encode it. Unlike the driver, a
+// nil filter or option document counts as empty.
+func toDoc(v interface{}) (bson.D, error) {
+	if v == nil {
+		return bson.D{}, nil
+	}
+	return bsonutil.ToDoc(v)
+}
+
+func cloneDoc(doc bson.D) bson.D {
+	c, err := toDoc(doc)
+	if err != nil {
+		// documents in the store were produced by toDoc, so this cannot fail
+		panic(err)
+	}
+	return c
+}
+
+func decode(doc bson.D, target interface{}) error {
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return bson.Unmarshal(data, target)
+}
+
+// decodeAll mirrors mongo.Cursor.All: results must be a pointer to a slice.
+func decodeAll(docs []bson.D, results interface{}) error {
+	rv := reflect.ValueOf(results)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("mongodbtest: results argument must be a pointer to a slice, but was a %s", rv.Kind())
+	}
+
+	slice := rv.Elem()
+	slice = slice.Slice(0, 0)
+	elemType := slice.Type().Elem()
+	for _, doc := range docs {
+		elem := reflect.New(elemType)
+		if err := decode(doc, elem.Interface()); err != nil {
+			return err
+		}
+		slice = reflect.Append(slice, elem.Elem())
+	}
+	rv.Elem().Set(slice)
+	return nil
+}
+
+// lookup resolves a dotted path, descending into arrays the way the query
+// engine does. It returns every value reachable through the path.
+func lookup(v interface{}, parts []string) []interface{} {
+	if len(parts) == 0 {
+		return []interface{}{v}
+	}
+
+	switch t := v.(type) {
+	case primitive.D:
+		child, ok := bsonutil.Lookup(t, parts[0])
+		if !ok {
+			return nil
+		}
+		return lookup(child, parts[1:])
+	case primitive.A:
+		if idx, err := strconv.Atoi(parts[0]); err == nil {
+			if idx < 0 || idx >= len(t) {
+				return nil
+			}
+			return lookup(t[idx], parts[1:])
+		}
+
+		var values []interface{}
+		for _, elem := range t {
+			if d, ok := elem.(primitive.D); ok {
+				values = append(values, lookup(d, parts)...)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+func lookupPath(doc bson.D, path string) []interface{} {
+	return lookup(doc, strings.Split(path, "."))
+}
+
+// getPath resolves a dotted path without expanding arrays, as the update
+// operators do.
+func getPath(doc bson.D, parts []string) (interface{}, bool) {
+	var cur interface{} = doc
+	for _, part := range parts {
+		switch t := cur.(type) {
+		case primitive.D:
+			v, ok := bsonutil.Lookup(t, part)
+			if !ok {
+				return nil, false
+			}
+			cur = v
+		case primitive.A:
+			idx, err := strconv.Atoi(part)
+			if err != nil || idx < 0 || idx >= len(t) {
+				return nil, false
+			}
+			cur = t[idx]
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+func setPath(doc bson.D, parts []string, value interface{}) (bson.D, error) {
+	for i, e := range doc {
+		if e.Key != parts[0] {
+			continue
+		}
+		if len(parts) == 1 {
+			doc[i].Value = value
+			return doc, nil
+		}
+
+		child, err := setChild(e.Value, parts[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		doc[i].Value = child
+		return doc, nil
+	}
+
+	if len(parts) == 1 {
+		return append(doc, bson.E{Key: parts[0], Value: value}), nil
+	}
+	child, err := setPath(bson.D{}, parts[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	return append(doc, bson.E{Key: parts[0], Value: child}), nil
+}
+
+func setChild(cur interface{}, parts []string, value interface{}) (interface{}, error) {
+	switch t := cur.(type) {
+	case primitive.D:
+		return setPath(t, parts, value)
+	case primitive.A:
+		idx, err := strconv.Atoi(parts[0])
+		if err != nil || idx < 0 {
+			return nil, fmt.Errorf("mongodbtest: cannot create field %q in array", parts[0])
+		}
+		for len(t) <= idx {
+			t = append(t, nil)
+		}
+		if len(parts) == 1 {
+			t[idx] = value
+			return t, nil
+		}
+		elem := t[idx]
+		if elem == nil {
+			elem = bson.D{}
+		}
+		child, err := setChild(elem, parts[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		t[idx] = child
+		return t, nil
+	}
+	return nil, fmt.Errorf("mongodbtest: cannot create field %q in element of type %T", parts[0], cur)
+}
+
+func unsetPath(doc bson.D, parts []string) bson.D {
+	for i, e := range doc {
+		if e.Key != parts[0] {
+			continue
+		}
+		if len(parts) == 1 {
+			return append(doc[:i:i], doc[i+1:]...)
+		}
+		switch t := e.Value.(type) {
+		case primitive.D:
+			doc[i].Value = unsetPath(t, parts[1:])
+		case primitive.A:
+			idx, err := strconv.Atoi(parts[1])
+			if err != nil || idx < 0 || idx >= len(t) {
+				return doc
+			}
+			if len(parts) == 2 {
+				t[idx] = nil
+			} else if d, ok := t[idx].(primitive.D); ok {
+				t[idx] = unsetPath(d, parts[2:])
+			}
+		}
+		return doc
+	}
+	return doc
+}
+
+// typeRank returns the position of v in MongoDB's cross-type comparison order.
+func typeRank(v interface{}) int {
+	switch v.(type) {
+	case primitive.MinKey:
+		return 1
+	case nil, primitive.Null, primitive.Undefined:
+		return 2
+	case int32, int64, float64, primitive.Decimal128:
+		return 3
+	case string, primitive.Symbol:
+		return 4
+	case primitive.D:
+		return 5
+	case primitive.A:
+		return 6
+	case primitive.Binary:
+		return 7
+	case primitive.ObjectID:
+		return 8
+	case bool:
+		return 9
+	case primitive.DateTime:
+		return 10
+	case primitive.Timestamp:
+		return 11
+	case primitive.Regex:
+		return 12
+	case primitive.MaxKey:
+		return 14
+	}
+	return 13
+}
+
+func compareValues(a, b interface{}) int {
+	ra, rb := typeRank(a), typeRank(b)
+	if ra != rb {
+		return compareInts(int64(ra), int64(rb))
+	}
+
+	switch x := a.(type) {
+	case int32, int64, float64, primitive.Decimal128:
+		return compareNumbers(x, b)
+	case string:
+		return strings.Compare(x, stringValue(b))
+	case primitive.Symbol:
+		return strings.Compare(string(x), stringValue(b))
+	case primitive.D:
+		y := b.(primitive.D)
+		for i := 0; i < len(x) && i < len(y); i++ {
+			if c := strings.Compare(x[i].Key, y[i].Key); c != 0 {
+				return c
+			}
+			if c := compareValues(x[i].Value, y[i].Value); c != 0 {
+				return c
+			}
+		}
+		return compareInts(int64(len(x)), int64(len(y)))
+	case primitive.A:
+		y := b.(primitive.A)
+		for i := 0; i < len(x) && i < len(y); i++ {
+			if c := compareValues(x[i], y[i]); c != 0 {
+				return c
+			}
+		}
+		return compareInts(int64(len(x)), int64(len(y)))
+	case primitive.Binary:
+		return bytes.Compare(x.Data, b.(primitive.Binary).Data)
+	case primitive.ObjectID:
+		y := b.(primitive.ObjectID)
+		return bytes.Compare(x[:], y[:])
+	case bool:
+		y := b.(bool)
+		if x == y {
+			return 0
+		}
+		if !x {
+			return -1
+		}
+		return 1
+	case primitive.DateTime:
+		return compareInts(int64(x), int64(b.(primitive.DateTime)))
+	case primitive.Timestamp:
+		return primitive.CompareTimestamp(x, b.(primitive.Timestamp))
+	case primitive.Regex:
+		y := b.(primitive.Regex)
+		if c := strings.Compare(x.Pattern, y.Pattern); c != 0 {
+			return c
+		}
+		return strings.Compare(x.Options, y.Options)
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return 0
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func stringValue(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case primitive.Symbol:
+		return string(t)
+	}
+	return ""
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareNumbers(a, b interface{}) int {
+	ai, aInt := intValue(a)
+	bi, bInt := intValue(b)
+	if aInt && bInt {
+		return compareInts(ai, bi)
+	}
+
+	af, bf := floatValue(a), floatValue(b)
+	switch {
+	case af < bf:
+		return -1
+	case af > bf:
+		return 1
+	}
+	return 0
+}
+
+func intValue(v interface{}) (int64, bool) {
+	switch t := v.(type) {
+	case int32:
+		return int64(t), true
+	case int64:
+		return t, true
+	}
+	return 0, false
+}
+
+func floatValue(v interface{}) float64 {
+	switch t := v.(type) {
+	case int32:
+		return float64(t)
+	case int64:
+		return float64(t)
+	case float64:
+		return t
+	case primitive.Decimal128:
+		f, err := strconv.ParseFloat(t.String(), 64)
+		if err != nil {
+			return math.NaN()
+		}
+		return f
+	}
+	return math.NaN()
+}
+
+func isNumber(v interface{}) bool {
+	return typeRank(v) == 3
+}
+
+func equalValues(a, b interface{}) bool {
+	return typeRank(a) == typeRank(b) && compareValues(a, b) == 0
+}
+
+func truthy(v interface{}) bool {
+	switch t := v.(type) {
+	case nil, primitive.Null, primitive.Undefined:
+		return false
+	case bool:
+		return t
+	case int32, int64, float64, primitive.Decimal128:
+		return floatValue(t) != 0
+	}
+	return true
+}
+
+// addNumbers implements $inc arithmetic, keeping the narrowest integer type
+// that can hold the result.
+func addNumbers(a, b interface{}) interface{} {
+	ai, aInt := intValue(a)
+	bi, bInt := intValue(b)
+	if aInt && bInt {
+		sum := ai + bi
+		_, a32 := a.(int32)
+		_, b32 := b.(int32)
+		if a32 && b32 && sum >= math.MinInt32 && sum <= math.MaxInt32 {
+			return int32(sum)
+		}
+		return sum
+	}
+	return floatValue(a) + floatValue(b)
+}
+
+func compileRegex(pattern, opts string) (*regexp.Regexp, error) {
+	var flags string
+	for _, o := range opts {
+		switch o {
+		case 'i', 'm', 's':
+			flags += string(o)
+		}
+	}
+	if flags != "" {
+		pattern = "(?" + flags + ")" + pattern
+	}
+	return regexp.Compile(pattern)
+}
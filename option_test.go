@@ -0,0 +1,48 @@
+package mongodb
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+func TestOptionsApplyInOrder(t *testing.T) {
+	cfg := newClientConfig(
+		WithURI("mongodb://localhost:27017/?maxPoolSize=10&appName=uri"),
+		WithPoolSize(2, 20),
+		WithTimeouts(Timeouts{Connect: time.Second, ServerSelection: 2 * time.Second}),
+		WithCompressors("zstd", "snappy"),
+		WithReadPreference(readpref.SecondaryPreferred()),
+		WithCredential(options.Credential{Username: "user", Password: "pwd"}),
+		WithRawClientOptions(options.Client().SetAppName("raw")),
+	)
+	got := options.MergeClientOptions(cfg.clientOpts...)
+
+	if !reflect.DeepEqual(got.Hosts, []string{"localhost:27017"}) {
+		t.Errorf("Hosts = %v", got.Hosts)
+	}
+	if *got.MinPoolSize != 2 || *got.MaxPoolSize != 20 {
+		t.Errorf("pool size = %d..%d, want 2..20", *got.MinPoolSize, *got.MaxPoolSize)
+	}
+	if *got.ConnectTimeout != time.Second || *got.ServerSelectionTimeout != 2*time.Second {
+		t.Errorf("timeouts = %v, %v", *got.ConnectTimeout, *got.ServerSelectionTimeout)
+	}
+	if got.SocketTimeout != nil {
+		t.Errorf("SocketTimeout = %v, want the driver default", *got.SocketTimeout)
+	}
+	if !reflect.DeepEqual(got.Compressors, []string{"zstd", "snappy"}) {
+		t.Errorf("Compressors = %v", got.Compressors)
+	}
+	if got.ReadPreference.Mode() != readpref.SecondaryPreferredMode {
+		t.Errorf("ReadPreference = %v", got.ReadPreference.Mode())
+	}
+	if got.Auth == nil || got.Auth.Username != "user" {
+		t.Errorf("Auth = %+v", got.Auth)
+	}
+	if *got.AppName != "raw" {
+		t.Errorf("AppName = %q, want the last option to win", *got.AppName)
+	}
+}
@@ -0,0 +1,145 @@
+package otel
+
+import (
+	"context"
+
+	"github.com/subratohld/mongodb"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WrapDatabase traces the operations of db. Collections opened from the
+// returned Database are traced too, so the client should not also register
+// Interceptor.
+func WrapDatabase(db mongodb.Database, opts ...Option) mongodb.Database {
+	cfg := newConfig(opts...)
+	return &database{
+		Database:    db,
+		tracer:      cfg.provider.Tracer(tracerName),
+		interceptor: Interceptor(opts...),
+	}
+}
+
+type database struct {
+	mongodb.Database
+	tracer      trace.Tracer
+	interceptor mongodb.Interceptor
+}
+
+// start opens a span for operation op on the database, naming coll if the
+// operation targets a single collection.
+func (db *database) start(ctx context.Context, op, coll string) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemMongoDB,
+		semconv.DBName(db.Name()),
+		semconv.DBOperation(op),
+	}
+	name := op + " " + db.Name()
+	if coll != "" {
+		attrs = append(attrs, semconv.DBMongoDBCollection(coll))
+		name += "." + coll
+	}
+	return db.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+func (db *database) Collection(name string, opts ...*options.CollectionOptions) mongodb.Collection {
+	return db.CollectionWith(name, mongodb.WithCollectionOptions(opts...))
+}
+
+func (db *database) CollectionWith(name string, opts ...mongodb.CollectionOption) mongodb.Collection {
+	opts = append([]mongodb.CollectionOption{mongodb.WithCollectionInterceptors(db.interceptor)}, opts...)
+	return db.Database.CollectionWith(name, opts...)
+}
+
+func (db *database) CreateCollection(ctx context.Context, name string, opts ...*options.CreateCollectionOptions) error {
+	ctx, span := db.start(ctx, "CreateCollection", name)
+	defer span.End()
+
+	err := db.Database.CreateCollection(ctx, name, opts...)
+	record(span, err)
+	return err
+}
+
+func (db *database) ListCollections(ctx context.Context, filter interface{}, opts ...*options.ListCollectionsOptions) (*mongo.Cursor, error) {
+	ctx, span := db.start(ctx, "ListCollections", "")
+	defer span.End()
+
+	cur, err := db.Database.ListCollections(ctx, filter, opts...)
+	record(span, err)
+	return cur, err
+}
+
+func (db *database) ListCollectionNames(ctx context.Context, filter interface{}, opts ...*options.ListCollectionsOptions) ([]string, error) {
+	ctx, span := db.start(ctx, "ListCollectionNames", "")
+	defer span.End()
+
+	names, err := db.Database.ListCollectionNames(ctx, filter, opts...)
+	record(span, err)
+	return names, err
+}
+
+func (db *database) ListCollectionSpecifications(ctx context.Context, filter interface{}, opts ...*options.ListCollectionsOptions) ([]*mongo.CollectionSpecification, error) {
+	ctx, span := db.start(ctx, "ListCollectionSpecifications", "")
+	defer span.End()
+
+	specs, err := db.Database.ListCollectionSpecifications(ctx, filter, opts...)
+	record(span, err)
+	return specs, err
+}
+
+func (db *database) CreateView(ctx context.Context, name, viewOn string, pipeline interface{}, opts ...*options.CreateViewOptions) error {
+	ctx, span := db.start(ctx, "CreateView", name)
+	defer span.End()
+
+	err := db.Database.CreateView(ctx, name, viewOn, pipeline, opts...)
+	record(span, err)
+	return err
+}
+
+func (db *database) Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (*mongo.Cursor, error) {
+	ctx, span := db.start(ctx, "Aggregate", "")
+	defer span.End()
+
+	cur, err := db.Database.Aggregate(ctx, pipeline, opts...)
+	record(span, err)
+	return cur, err
+}
+
+func (db *database) Drop(ctx context.Context) error {
+	ctx, span := db.start(ctx, "Drop", "")
+	defer span.End()
+
+	err := db.Database.Drop(ctx)
+	record(span, err)
+	return err
+}
+
+func (db *database) RunCommand(ctx context.Context, runCmd interface{}, opts ...*options.RunCmdOptions) *mongo.SingleResult {
+	ctx, span := db.start(ctx, "RunCommand", "")
+	defer span.End()
+
+	res := db.Database.RunCommand(ctx, runCmd, opts...)
+	record(span, res.Err())
+	return res
+}
+
+func (db *database) RunCommandCursor(ctx context.Context, runCmd interface{}, opts ...*options.RunCmdOptions) (*mongo.Cursor, error) {
+	ctx, span := db.start(ctx, "RunCommandCursor", "")
+	defer span.End()
+
+	cur, err := db.Database.RunCommandCursor(ctx, runCmd, opts...)
+	record(span, err)
+	return cur, err
+}
+
+func (db *database) Watch(ctx context.Context, pipeline interface{}, opts ...*options.ChangeStreamOptions) (mongodb.ChangeStream, error) {
+	ctx, span := db.start(ctx, "Watch", "")
+	defer span.End()
+
+	cs, err := db.Database.Watch(ctx, pipeline, opts...)
+	record(span, err)
+	return cs, err
+}
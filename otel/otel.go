@@ -0,0 +1,95 @@
+// Package otel traces MongoDB operations with OpenTelemetry.
+//
+//	client, err := mongodb.NewClient(ctx,
+//		mongodb.WithURI(uri),
+//		mongodb.WithInterceptors(otel.Interceptor()),
+//	)
+//	db := otel.WrapDatabase(client.Database("orders"))
+package otel
+
+import (
+	"context"
+	"errors"
+
+	"github.com/subratohld/mongodb"
+	"go.mongodb.org/mongo-driver/mongo"
+	global "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/subratohld/mongodb/otel"
+
+type Option func(*config)
+
+type config struct {
+	provider  trace.TracerProvider
+	statement bool
+}
+
+func newConfig(opts ...Option) *config {
+	cfg := &config{statement: true}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.provider == nil {
+		cfg.provider = global.GetTracerProvider()
+	}
+	return cfg
+}
+
+// WithTracerProvider sets the provider spans are created from. The global
+// provider is used by default.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(cfg *config) {
+		cfg.provider = tp
+	}
+}
+
+// WithoutStatement leaves the db.statement attribute out of spans.
+func WithoutStatement() Option {
+	return func(cfg *config) {
+		cfg.statement = false
+	}
+}
+
+// Interceptor returns a mongodb.Interceptor that runs every collection
+// operation in a client span. The span is the parent of anything started
+// from the context the operation receives.
+func Interceptor(opts ...Option) mongodb.Interceptor {
+	cfg := newConfig(opts...)
+	tracer := cfg.provider.Tracer(tracerName)
+
+	return func(ctx context.Context, op mongodb.OperationInfo, next mongodb.Invoker) error {
+		attrs := []attribute.KeyValue{
+			semconv.DBSystemMongoDB,
+			semconv.DBName(op.Database),
+			semconv.DBMongoDBCollection(op.Collection),
+			semconv.DBOperation(op.Name),
+		}
+		if cfg.statement {
+			if stmt := statement(op); stmt != "" {
+				attrs = append(attrs, semconv.DBStatement(stmt))
+			}
+		}
+
+		ctx, span := tracer.Start(ctx, op.Name+" "+op.Namespace(), trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+		defer span.End()
+
+		err := next(ctx, op)
+		record(span, err)
+		return err
+	}
+}
+
+// record marks span as failed by err. A missing document is an ordinary
+// outcome rather than a failure.
+func record(span trace.Span, err error) {
+	if err == nil || errors.Is(err, mongo.ErrNoDocuments) {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
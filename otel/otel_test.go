@@ -0,0 +1,103 @@
+package otel_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/subratohld/mongodb"
+	"github.com/subratohld/mongodb/mongodbtest"
+	"github.com/subratohld/mongodb/otel"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newProvider() (*sdktrace.TracerProvider, *tracetest.SpanRecorder) {
+	sr := tracetest.NewSpanRecorder()
+	return sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)), sr
+}
+
+func attrs(span sdktrace.ReadOnlySpan) map[attribute.Key]string {
+	m := make(map[attribute.Key]string)
+	for _, kv := range span.Attributes() {
+		m[kv.Key] = kv.Value.Emit()
+	}
+	return m
+}
+
+func TestInterceptor(t *testing.T) {
+	tp, sr := newProvider()
+	coll := mongodbtest.NewClient().Database("shop").CollectionWith("orders",
+		mongodb.WithCollectionInterceptors(otel.Interceptor(otel.WithTracerProvider(tp))))
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	if _, err := coll.UpdateOne(ctx, bson.M{"customer": "c1"}, bson.M{"$set": bson.M{"status": "paid"}}); err != nil {
+		t.Fatal(err)
+	}
+	var doc bson.M
+	if err := coll.FindOne(ctx, bson.M{"_id": "missing"}, &doc); err != mongo.ErrNoDocuments {
+		t.Fatal(err)
+	}
+	if _, err := coll.InsertMany(ctx, nil); err == nil {
+		t.Fatal("InsertMany with no documents succeeded")
+	}
+	parent.End()
+
+	spans := sr.Ended()
+	if len(spans) != 4 {
+		t.Fatalf("got %d spans, want 4", len(spans))
+	}
+
+	update := spans[0]
+	if update.Name() != "UpdateOne shop.orders" || update.SpanKind() != trace.SpanKindClient {
+		t.Fatalf("span = %q, kind %v", update.Name(), update.SpanKind())
+	}
+	if update.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Fatal("span is not a child of the caller's span")
+	}
+	want := map[attribute.Key]string{
+		"db.system":             "mongodb",
+		"db.name":               "shop",
+		"db.mongodb.collection": "orders",
+		"db.operation":          "UpdateOne",
+		"db.statement":          `{"filter": {"customer": ?}, "update": {"$set": {"status": ?}}}`,
+	}
+	got := attrs(update)
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+
+	if find := spans[1]; find.Status().Code == codes.Error {
+		t.Error("a missing document marked the span as failed")
+	}
+	if insert := spans[2]; insert.Status().Code != codes.Error || len(insert.Events()) == 0 {
+		t.Errorf("failed InsertMany span status = %v", insert.Status())
+	}
+}
+
+func TestWrapDatabase(t *testing.T) {
+	tp, sr := newProvider()
+	db := otel.WrapDatabase(mongodbtest.NewClient().Database("shop"), otel.WithTracerProvider(tp), otel.WithoutStatement())
+
+	ctx := context.Background()
+	if err := db.CreateCollection(ctx, "orders"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Collection("orders").CountDocuments(ctx, bson.M{"status": "paid"}); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := sr.Ended()
+	if len(spans) != 2 || spans[0].Name() != "CreateCollection shop.orders" || spans[1].Name() != "CountDocuments shop.orders" {
+		t.Fatalf("spans = %v", spans)
+	}
+	if _, ok := attrs(spans[1])["db.statement"]; ok {
+		t.Error("db.statement recorded with WithoutStatement")
+	}
+}
@@ -0,0 +1,73 @@
+package otel
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/subratohld/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// statement describes the shape of op as JSON, e.g.
+// {"filter": {"age": {"$gt": ?}}}, with every value replaced by "?" so that
+// no document data ends up in traces. Documents being inserted are left out.
+func statement(op mongodb.OperationInfo) string {
+	var parts []string
+	add := func(name string, v interface{}) {
+		if v == nil {
+			return
+		}
+		if s, ok := sanitize(v); ok {
+			parts = append(parts, strconv.Quote(name)+": "+s)
+		}
+	}
+	add("filter", op.Filter)
+	add("update", op.Update)
+	add("pipeline", op.Pipeline)
+
+	if len(parts) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+func sanitize(v interface{}) (string, bool) {
+	t, data, err := bson.MarshalValue(v)
+	if err != nil {
+		return "", false
+	}
+
+	var b strings.Builder
+	write(&b, bson.RawValue{Type: t, Value: data})
+	return b.String(), true
+}
+
+func write(b *strings.Builder, v bson.RawValue) {
+	switch v.Type {
+	case bsontype.EmbeddedDocument:
+		elems, _ := v.Document().Elements()
+		b.WriteByte('{')
+		for i, e := range elems {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(strconv.Quote(e.Key()))
+			b.WriteString(": ")
+			write(b, e.Value())
+		}
+		b.WriteByte('}')
+	case bsontype.Array:
+		values, _ := v.Array().Values()
+		b.WriteByte('[')
+		for i, e := range values {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			write(b, e)
+		}
+		b.WriteByte(']')
+	default:
+		b.WriteByte('?')
+	}
+}
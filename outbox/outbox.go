@@ -0,0 +1,144 @@
+// Package outbox implements the transactional outbox pattern on top of a
+// mongodb.Collection.
+//
+// Events are written to an outbox collection with Enqueue in the same
+// transaction as the business data they describe, and a Relay later hands
+// them to a Publisher. Delivery is at least once: a relay that stops after
+// publishing but before marking an event delivered publishes it again.
+//
+//	err := client.WithTransaction(ctx, func(tx mongodb.TxContext) error {
+//		if _, err := tx.Database("shop").Collection("orders").InsertOne(tx, order); err != nil {
+//			return err
+//		}
+//		_, err := box.Enqueue(tx, outbox.Event{Topic: "order.created", Key: order.ID, Payload: order})
+//		return err
+//	})
+package outbox
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/subratohld/mongodb"
+	"github.com/subratohld/mongodb/internal/clock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrNoSession is returned by Enqueue when it is called outside of a
+// session, where the event could not be committed together with the write it
+// belongs to.
+var ErrNoSession = errors.New("outbox: Enqueue requires a session context")
+
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusDelivered Status = "delivered"
+	// StatusDead marks an event the relay gave up on after its last
+	// attempt. Dead events stay in the collection until they are requeued
+	// or removed.
+	StatusDead Status = "dead"
+)
+
+// Event is an event to be published.
+type Event struct {
+	Topic   string
+	Key     string
+	Headers map[string]string
+	// Payload is stored as a BSON value.
+	Payload interface{}
+}
+
+// Record is an event as stored in the outbox collection.
+type Record struct {
+	ID            primitive.ObjectID `bson:"_id"`
+	Topic         string             `bson:"topic"`
+	Key           string             `bson:"key,omitempty"`
+	Headers       map[string]string  `bson:"headers,omitempty"`
+	Payload       bson.RawValue      `bson:"payload"`
+	Status        Status             `bson:"status"`
+	Attempts      int                `bson:"attempts"`
+	LastError     string             `bson:"lastError,omitempty"`
+	CreatedAt     time.Time          `bson:"createdAt"`
+	NextAttemptAt time.Time          `bson:"nextAttemptAt"`
+	DeliveredAt   time.Time          `bson:"deliveredAt,omitempty"`
+}
+
+type Outbox interface {
+	// Enqueue stores event as pending. ctx must carry the session of the
+	// surrounding transaction, such as the mongodb.TxContext passed to
+	// Client.WithTransaction or a mongo.SessionContext.
+	Enqueue(ctx context.Context, event Event) (primitive.ObjectID, error)
+	// Requeue makes a dead event pending again with a fresh attempt count.
+	Requeue(ctx context.Context, id primitive.ObjectID) error
+	// EnsureIndexes creates the index the relay uses to find due events.
+	EnsureIndexes(ctx context.Context) error
+}
+
+// New returns an Outbox storing events in coll.
+func New(coll mongodb.Collection) Outbox {
+	return &outbox{
+		coll: coll,
+	}
+}
+
+type outbox struct {
+	coll mongodb.Collection
+}
+
+func (o *outbox) Enqueue(ctx context.Context, event Event) (primitive.ObjectID, error) {
+	if _, ok := ctx.(mongodb.TxContext); !ok && mongo.SessionFromContext(ctx) == nil {
+		return primitive.NilObjectID, ErrNoSession
+	}
+	if event.Topic == "" {
+		return primitive.NilObjectID, errors.New("outbox: event topic is required")
+	}
+
+	id := primitive.NewObjectID()
+	now := clock.From(ctx)
+	doc := bson.D{
+		{Key: "_id", Value: id},
+		{Key: "topic", Value: event.Topic},
+		{Key: "key", Value: event.Key},
+		{Key: "headers", Value: event.Headers},
+		{Key: "payload", Value: event.Payload},
+		{Key: "status", Value: StatusPending},
+		{Key: "attempts", Value: 0},
+		{Key: "createdAt", Value: now},
+		{Key: "nextAttemptAt", Value: now},
+	}
+	if _, err := o.coll.InsertOne(ctx, doc); err != nil {
+		return primitive.NilObjectID, err
+	}
+	return id, nil
+}
+
+func (o *outbox) Requeue(ctx context.Context, id primitive.ObjectID) error {
+	res, err := o.coll.UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: id}, {Key: "status", Value: StatusDead}},
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "status", Value: StatusPending},
+			{Key: "attempts", Value: 0},
+			{Key: "nextAttemptAt", Value: clock.From(ctx)},
+		}}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (o *outbox) EnsureIndexes(ctx context.Context) error {
+	_, err := o.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "status", Value: 1}, {Key: "nextAttemptAt", Value: 1}},
+		Options: options.Index().SetName("outbox_due"),
+	})
+	return err
+}
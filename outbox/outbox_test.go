@@ -0,0 +1,178 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/subratohld/mongodb"
+	"github.com/subratohld/mongodb/internal/clock"
+	"github.com/subratohld/mongodb/mongodbtest"
+	"github.com/subratohld/mongodb/outbox"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type order struct {
+	ID    string `bson:"_id"`
+	Total int    `bson:"total"`
+}
+
+func setup(t *testing.T) (mongodb.Client, mongodb.Collection, outbox.Outbox) {
+	t.Helper()
+
+	client := mongodbtest.NewClient()
+	coll := client.Database("shop").Collection("outbox")
+	box := outbox.New(coll)
+	if err := box.EnsureIndexes(context.TODO()); err != nil {
+		t.Fatalf("EnsureIndexes: %v", err)
+	}
+	return client, coll, box
+}
+
+func record(t *testing.T, coll mongodb.Collection) outbox.Record {
+	t.Helper()
+
+	var rec outbox.Record
+	if err := coll.FindOne(context.TODO(), bson.M{}, &rec); err != nil {
+		t.Fatalf("FindOne: %v", err)
+	}
+	return rec
+}
+
+func TestEnqueueRequiresSession(t *testing.T) {
+	_, _, box := setup(t)
+
+	if _, err := box.Enqueue(context.TODO(), outbox.Event{Topic: "order.created"}); !errors.Is(err, outbox.ErrNoSession) {
+		t.Errorf("Enqueue err = %v, want ErrNoSession", err)
+	}
+}
+
+func TestEnqueueAndRelay(t *testing.T) {
+	client, coll, box := setup(t)
+	ctx := context.TODO()
+
+	enqueue := func(o order, fail bool) error {
+		return client.WithTransaction(ctx, func(tx mongodb.TxContext) error {
+			if _, err := tx.Database("shop").Collection("orders").InsertOne(tx, o); err != nil {
+				return err
+			}
+			if _, err := box.Enqueue(tx, outbox.Event{Topic: "order.created", Key: o.ID, Payload: o}); err != nil {
+				return err
+			}
+			if fail {
+				return errors.New("rollback")
+			}
+			return nil
+		})
+	}
+	if err := enqueue(order{ID: "o1", Total: 42}, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := enqueue(order{ID: "o2", Total: 7}, true); err == nil {
+		t.Fatal("expected the second transaction to fail")
+	}
+
+	var published []order
+	relay := outbox.NewRelay(coll, outbox.PublisherFunc(func(ctx context.Context, rec outbox.Record) error {
+		var o order
+		if err := rec.Payload.Unmarshal(&o); err != nil {
+			return err
+		}
+		published = append(published, o)
+		return nil
+	}))
+
+	n, err := relay.Process(ctx)
+	if err != nil || n != 1 {
+		t.Fatalf("Process = %d, %v", n, err)
+	}
+	if len(published) != 1 || published[0] != (order{ID: "o1", Total: 42}) {
+		t.Errorf("published = %+v", published)
+	}
+	if rec := record(t, coll); rec.Status != outbox.StatusDelivered || rec.Attempts != 1 || rec.DeliveredAt.IsZero() {
+		t.Errorf("record = %+v", rec)
+	}
+
+	if n, err := relay.Process(ctx); err != nil || n != 0 {
+		t.Errorf("second Process = %d, %v", n, err)
+	}
+}
+
+func TestRelayRetriesAndDeadLetters(t *testing.T) {
+	client, coll, box := setup(t)
+	ctx := context.TODO()
+
+	err := client.WithTransaction(ctx, func(tx mongodb.TxContext) error {
+		_, err := box.Enqueue(tx, outbox.Event{Topic: "order.created", Payload: bson.M{"id": "o1"}})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	relay := outbox.NewRelay(coll, outbox.PublisherFunc(func(ctx context.Context, rec outbox.Record) error {
+		return errors.New("broker unavailable")
+	}), outbox.WithMaxAttempts(2), outbox.WithRetryBackoff(time.Millisecond, time.Millisecond))
+
+	if _, err := relay.Process(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if rec := record(t, coll); rec.Status != outbox.StatusPending || rec.Attempts != 1 || rec.LastError != "broker unavailable" {
+		t.Fatalf("record after first attempt = %+v", rec)
+	}
+
+	// The retry is due once the backoff has passed.
+	if _, err := relay.Process(clock.With(ctx, clock.Now().Add(time.Minute))); err != nil {
+		t.Fatal(err)
+	}
+	rec := record(t, coll)
+	if rec.Status != outbox.StatusDead || rec.Attempts != 2 {
+		t.Fatalf("record after last attempt = %+v", rec)
+	}
+
+	if err := box.Requeue(ctx, rec.ID); err != nil {
+		t.Fatalf("Requeue: %v", err)
+	}
+	if rec := record(t, coll); rec.Status != outbox.StatusPending || rec.Attempts != 0 {
+		t.Errorf("record after Requeue = %+v", rec)
+	}
+}
+
+func TestRelayRunWatch(t *testing.T) {
+	client, coll, box := setup(t)
+
+	published := make(chan string, 1)
+	relay := outbox.NewRelay(coll, outbox.PublisherFunc(func(ctx context.Context, rec outbox.Record) error {
+		published <- rec.Key
+		return nil
+	}), outbox.WithWatch(), outbox.WithPollInterval(time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	done := make(chan error)
+	go func() { done <- relay.Run(ctx) }()
+
+	err := client.WithTransaction(ctx, func(tx mongodb.TxContext) error {
+		_, err := box.Enqueue(tx, outbox.Event{Topic: "order.created", Key: "o1"})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case key := <-published:
+		if key != "o1" {
+			t.Errorf("published %q, want o1", key)
+		}
+	case <-ctx.Done():
+		t.Fatal("event was not published")
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,244 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/subratohld/mongodb"
+	"github.com/subratohld/mongodb/internal/clock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Publisher delivers events to the outside world, e.g. a message broker.
+type Publisher interface {
+	Publish(ctx context.Context, rec Record) error
+}
+
+// PublisherFunc adapts a function to a Publisher.
+type PublisherFunc func(ctx context.Context, rec Record) error
+
+func (f PublisherFunc) Publish(ctx context.Context, rec Record) error {
+	return f(ctx, rec)
+}
+
+// Relay moves pending events from the outbox collection to a Publisher.
+// Several relays may share a collection: each event is leased to one relay
+// while it is being published.
+type Relay interface {
+	// Run processes events until ctx is done or the outbox collection
+	// cannot be read.
+	Run(ctx context.Context) error
+	// Process publishes every event that is due and returns the number of
+	// events it attempted.
+	Process(ctx context.Context) (int, error)
+}
+
+type RelayOption func(*relayConfig)
+
+type relayConfig struct {
+	pollInterval time.Duration
+	watch        bool
+	maxAttempts  int
+	backoff      time.Duration
+	maxBackoff   time.Duration
+	lease        time.Duration
+}
+
+// WithPollInterval sets how often Run looks for due events. Defaults to one
+// second.
+func WithPollInterval(d time.Duration) RelayOption {
+	return func(cfg *relayConfig) {
+		cfg.pollInterval = d
+	}
+}
+
+// WithWatch makes Run process new events as soon as a change stream reports
+// them. Polling continues for retries, so the interval can be raised. Change
+// streams need a replica set or sharded cluster.
+func WithWatch() RelayOption {
+	return func(cfg *relayConfig) {
+		cfg.watch = true
+	}
+}
+
+// WithMaxAttempts sets how often an event is published before it is marked
+// StatusDead. Defaults to 10.
+func WithMaxAttempts(n int) RelayOption {
+	return func(cfg *relayConfig) {
+		cfg.maxAttempts = n
+	}
+}
+
+// WithRetryBackoff sets the wait before the second attempt of an event. The
+// wait doubles on every further attempt up to max. Defaults to one second and
+// five minutes.
+func WithRetryBackoff(initial, max time.Duration) RelayOption {
+	return func(cfg *relayConfig) {
+		cfg.backoff = initial
+		cfg.maxBackoff = max
+	}
+}
+
+// WithLease sets how long an event is reserved for the relay publishing it.
+// If the relay has not recorded the outcome by then, the event is attempted
+// again. Defaults to one minute.
+func WithLease(d time.Duration) RelayOption {
+	return func(cfg *relayConfig) {
+		cfg.lease = d
+	}
+}
+
+// NewRelay returns a Relay publishing the events stored in coll with pub.
+func NewRelay(coll mongodb.Collection, pub Publisher, opts ...RelayOption) Relay {
+	cfg := relayConfig{
+		pollInterval: time.Second,
+		maxAttempts:  10,
+		backoff:      time.Second,
+		maxBackoff:   5 * time.Minute,
+		lease:        time.Minute,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.backoff < time.Millisecond {
+		cfg.backoff = time.Millisecond
+	}
+
+	return &relay{
+		coll: coll,
+		pub:  pub,
+		cfg:  cfg,
+	}
+}
+
+type relay struct {
+	coll mongodb.Collection
+	pub  Publisher
+	cfg  relayConfig
+}
+
+func (r *relay) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wake <-chan struct{}
+	var streamErr error
+	if r.cfg.watch {
+		cs, err := r.coll.Watch(ctx, mongo.Pipeline{{{Key: "$match", Value: bson.D{{Key: "operationType", Value: "insert"}}}}})
+		if err != nil {
+			return err
+		}
+
+		ch := make(chan struct{}, 1)
+		go func() {
+			defer close(ch)
+			defer cs.Close(context.Background())
+			for cs.Next(ctx) {
+				select {
+				case ch <- struct{}{}:
+				default:
+				}
+			}
+			streamErr = cs.Err()
+		}()
+		wake = ch
+	}
+
+	ticker := time.NewTicker(r.cfg.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Process(ctx); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case _, ok := <-wake:
+			if !ok {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if streamErr == nil {
+					streamErr = errors.New("outbox: change stream closed")
+				}
+				return streamErr
+			}
+		}
+	}
+}
+
+func (r *relay) Process(ctx context.Context) (int, error) {
+	start := clock.From(ctx)
+	for n := 0; ; n++ {
+		rec, err := r.claim(ctx, start)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		if err := r.deliver(ctx, rec); err != nil {
+			return n + 1, err
+		}
+	}
+}
+
+// claim leases the oldest pending event that was due at start.
+func (r *relay) claim(ctx context.Context, start time.Time) (Record, error) {
+	var rec Record
+	err := r.coll.FindOneAndUpdate(ctx,
+		bson.D{
+			{Key: "status", Value: StatusPending},
+			{Key: "nextAttemptAt", Value: bson.D{{Key: "$lte", Value: start}}},
+		},
+		bson.D{
+			{Key: "$set", Value: bson.D{{Key: "nextAttemptAt", Value: clock.From(ctx).Add(r.cfg.lease)}}},
+			{Key: "$inc", Value: bson.D{{Key: "attempts", Value: 1}}},
+		},
+		&rec,
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}).
+			SetReturnDocument(options.After),
+	)
+	return rec, err
+}
+
+// deliver publishes rec and records the outcome. The update only applies
+// while the lease taken by claim is still held.
+func (r *relay) deliver(ctx context.Context, rec Record) error {
+	var set bson.D
+	pubErr := r.pub.Publish(ctx, rec)
+	switch {
+	case pubErr == nil:
+		set = bson.D{{Key: "status", Value: StatusDelivered}, {Key: "deliveredAt", Value: clock.From(ctx)}}
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case r.cfg.maxAttempts > 0 && rec.Attempts >= r.cfg.maxAttempts:
+		set = bson.D{{Key: "status", Value: StatusDead}, {Key: "lastError", Value: pubErr.Error()}}
+	default:
+		set = bson.D{{Key: "nextAttemptAt", Value: clock.From(ctx).Add(r.retryAfter(rec.Attempts))}, {Key: "lastError", Value: pubErr.Error()}}
+	}
+
+	_, err := r.coll.UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: rec.ID}, {Key: "attempts", Value: rec.Attempts}},
+		bson.D{{Key: "$set", Value: set}},
+	)
+	return err
+}
+
+func (r *relay) retryAfter(attempts int) time.Duration {
+	wait := r.cfg.backoff
+	for i := 1; i < attempts && wait < r.cfg.maxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > r.cfg.maxBackoff {
+		wait = r.cfg.maxBackoff
+	}
+	return wait
+}
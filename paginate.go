@@ -0,0 +1,254 @@
+package mongodb
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrInvalidPageToken is returned by Paginate for a page token that was not
+// issued for the same secret and sort order, or has been modified.
+var ErrInvalidPageToken = errors.New("mongodb: invalid page token")
+
+const defaultPageSize = 20
+
+// PageRequest selects a page for Paginate.
+type PageRequest struct {
+	// Sort is the page order. _id is appended as a final ascending key
+	// unless present, so that every document has a distinct position.
+	// Sort fields must be present and non-null in every document.
+	Sort bson.D
+	// Size is the number of documents per page. Defaults to 20.
+	Size int64
+	// Token is the NextToken of the previous page, or empty for the first
+	// page.
+	Token string
+	// Secret signs page tokens, so clients cannot forge positions.
+	Secret []byte
+	// Projection must keep the sort fields.
+	Projection interface{}
+}
+
+// Page describes a page returned by Paginate.
+type Page struct {
+	// NextToken fetches the following page. It is empty on the last page.
+	NextToken string
+}
+
+// Paginate decodes one page of the documents matching filter into results,
+// which must be a pointer to a slice. Pages are found by the sort key of the
+// last document of the previous page instead of by skipping documents, so
+// every page is as cheap to fetch as the first given an index on the sort
+// fields.
+func Paginate(ctx context.Context, coll Collection, filter interface{}, req PageRequest, results interface{}) (*Page, error) {
+	if len(req.Secret) == 0 {
+		return nil, errors.New("mongodb: a page token secret is required")
+	}
+	size := req.Size
+	if size <= 0 {
+		size = defaultPageSize
+	}
+	sort := keysetSort(req.Sort)
+
+	if req.Token != "" {
+		after, err := decodePageToken(req.Token, req.Secret, sort)
+		if err != nil {
+			return nil, err
+		}
+		cond := keysetCondition(sort, after)
+		if filter == nil {
+			filter = cond
+		} else {
+			filter = bson.D{{Key: "$and", Value: bson.A{filter, cond}}}
+		}
+	}
+
+	opts := options.Find().SetSort(sort).SetLimit(size + 1)
+	if req.Projection != nil {
+		opts.SetProjection(req.Projection)
+	}
+
+	var docs []bson.Raw
+	if err := coll.Find(ctx, orEmpty(filter), &docs, opts); err != nil {
+		return nil, err
+	}
+
+	page := &Page{}
+	if int64(len(docs)) > size {
+		docs = docs[:size]
+		token, err := encodePageToken(docs[len(docs)-1], req.Secret, sort)
+		if err != nil {
+			return nil, err
+		}
+		page.NextToken = token
+	}
+	return page, decodeRaws(docs, results)
+}
+
+// OffsetPage describes a page returned by PaginateOffset.
+type OffsetPage struct {
+	Number int64
+	Size   int64
+	Total  int64
+	Pages  int64
+}
+
+// PaginateOffset decodes page number (counted from 1) of the documents
+// matching filter into results, and counts all matching documents. It skips
+// over the preceding pages, so it is only suited to small collections.
+func PaginateOffset(ctx context.Context, coll Collection, filter interface{}, sort bson.D, number, size int64, results interface{}) (*OffsetPage, error) {
+	if number < 1 {
+		number = 1
+	}
+	if size <= 0 {
+		size = defaultPageSize
+	}
+
+	total, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().SetSort(keysetSort(sort)).SetSkip((number - 1) * size).SetLimit(size)
+	if err := coll.Find(ctx, orEmpty(filter), results, opts); err != nil {
+		return nil, err
+	}
+
+	return &OffsetPage{
+		Number: number,
+		Size:   size,
+		Total:  total,
+		Pages:  (total + size - 1) / size,
+	}, nil
+}
+
+func keysetSort(sort bson.D) bson.D {
+	for _, e := range sort {
+		if e.Key == "_id" {
+			return sort
+		}
+	}
+	return append(sort[:len(sort):len(sort)], bson.E{Key: "_id", Value: 1})
+}
+
+func descending(direction interface{}) bool {
+	switch v := direction.(type) {
+	case int:
+		return v < 0
+	case int32:
+		return v < 0
+	case int64:
+		return v < 0
+	case float64:
+		return v < 0
+	}
+	return false
+}
+
+// keysetCondition matches the documents sorting after the key values after:
+// those greater on the first key, or equal on it and greater on the second,
+// and so on.
+func keysetCondition(sort bson.D, after []bson.RawValue) bson.D {
+	or := make(bson.A, len(sort))
+	for i, key := range sort {
+		cond := make(bson.D, 0, i+1)
+		for j := 0; j < i; j++ {
+			cond = append(cond, bson.E{Key: sort[j].Key, Value: after[j]})
+		}
+		op := "$gt"
+		if descending(key.Value) {
+			op = "$lt"
+		}
+		cond = append(cond, bson.E{Key: key.Key, Value: bson.D{{Key: op, Value: after[i]}}})
+		or[i] = cond
+	}
+	return bson.D{{Key: "$or", Value: or}}
+}
+
+type pageToken struct {
+	Sort string          `bson:"s"`
+	Keys []bson.RawValue `bson:"k"`
+}
+
+func sortSignature(sort bson.D) string {
+	parts := make([]string, len(sort))
+	for i, e := range sort {
+		dir := "1"
+		if descending(e.Value) {
+			dir = "-1"
+		}
+		parts[i] = e.Key + ":" + dir
+	}
+	return strings.Join(parts, ",")
+}
+
+func encodePageToken(last bson.Raw, secret []byte, sort bson.D) (string, error) {
+	tok := pageToken{Sort: sortSignature(sort), Keys: make([]bson.RawValue, len(sort))}
+	for i, e := range sort {
+		v, err := last.LookupErr(strings.Split(e.Key, ".")...)
+		if err != nil {
+			return "", fmt.Errorf("mongodb: sort field %q is missing from the page: %w", e.Key, err)
+		}
+		tok.Keys[i] = v
+	}
+
+	payload, err := bson.Marshal(tok)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(sign(payload, secret)), nil
+}
+
+func decodePageToken(token string, secret []byte, sort bson.D) ([]bson.RawValue, error) {
+	enc := base64.RawURLEncoding
+	p, s, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidPageToken
+	}
+	payload, err := enc.DecodeString(p)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	sig, err := enc.DecodeString(s)
+	if err != nil || !hmac.Equal(sig, sign(payload, secret)) {
+		return nil, ErrInvalidPageToken
+	}
+
+	var tok pageToken
+	if err := bson.Unmarshal(payload, &tok); err != nil || tok.Sort != sortSignature(sort) || len(tok.Keys) != len(sort) {
+		return nil, ErrInvalidPageToken
+	}
+	return tok.Keys, nil
+}
+
+func sign(payload, secret []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// decodeRaws decodes docs into results, a pointer to a slice.
+func decodeRaws(docs []bson.Raw, results interface{}) error {
+	rv := reflect.ValueOf(results)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return errors.New("mongodb: results must be a pointer to a slice")
+	}
+
+	slice := reflect.MakeSlice(rv.Elem().Type(), len(docs), len(docs))
+	for i, doc := range docs {
+		if err := bson.Unmarshal(doc, slice.Index(i).Addr().Interface()); err != nil {
+			return err
+		}
+	}
+	rv.Elem().Set(slice)
+	return nil
+}
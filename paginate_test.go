@@ -0,0 +1,102 @@
+package mongodb_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/subratohld/mongodb"
+	"github.com/subratohld/mongodb/mongodbtest"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func seedUsers(t *testing.T) mongodb.Collection {
+	t.Helper()
+
+	coll := mongodbtest.NewClient().Database("testdb").Collection("users")
+	_, err := coll.InsertMany(context.TODO(), []interface{}{
+		user{Id: "u1", Name: "Priya", Age: 22},
+		user{Id: "u2", Name: "Shekhar", Age: 25},
+		user{Id: "u3", Name: "Anil", Age: 25},
+		user{Id: "u4", Name: "Subrato", Age: 31},
+		user{Id: "u5", Name: "Anil", Age: 25},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return coll
+}
+
+func TestPaginate(t *testing.T) {
+	coll := seedUsers(t)
+	ctx := context.TODO()
+	req := mongodb.PageRequest{
+		Sort:   bson.D{{Key: "age", Value: -1}, {Key: "name", Value: 1}},
+		Size:   2,
+		Secret: []byte("secret"),
+	}
+
+	var ids []string
+	for pages := 0; ; pages++ {
+		if pages == 3 {
+			t.Fatal("pagination did not end after 3 pages")
+		}
+
+		var users []user
+		page, err := mongodb.Paginate(ctx, coll, bson.M{"age": bson.M{"$gte": 22}}, req, &users)
+		if err != nil {
+			t.Fatalf("Paginate: %v", err)
+		}
+		for _, u := range users {
+			ids = append(ids, u.Id)
+		}
+		if page.NextToken == "" {
+			break
+		}
+		req.Token = page.NextToken
+	}
+
+	if want := []string{"u4", "u3", "u5", "u2", "u1"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("ids = %v, want %v", ids, want)
+	}
+}
+
+func TestPaginateRejectsForeignTokens(t *testing.T) {
+	coll := seedUsers(t)
+	ctx := context.TODO()
+	req := mongodb.PageRequest{Sort: bson.D{{Key: "age", Value: 1}}, Size: 2, Secret: []byte("secret")}
+
+	var users []user
+	page, err := mongodb.Paginate(ctx, coll, nil, req, &users)
+	if err != nil || page.NextToken == "" {
+		t.Fatalf("Paginate = %+v, %v", page, err)
+	}
+
+	tests := map[string]mongodb.PageRequest{
+		"tampered":   {Sort: req.Sort, Secret: req.Secret, Token: "x" + page.NextToken},
+		"secret":     {Sort: req.Sort, Secret: []byte("other"), Token: page.NextToken},
+		"other sort": {Sort: bson.D{{Key: "age", Value: -1}}, Secret: req.Secret, Token: page.NextToken},
+	}
+	for name, req := range tests {
+		if _, err := mongodb.Paginate(ctx, coll, nil, req, &users); !errors.Is(err, mongodb.ErrInvalidPageToken) {
+			t.Errorf("%s: err = %v, want ErrInvalidPageToken", name, err)
+		}
+	}
+}
+
+func TestPaginateOffset(t *testing.T) {
+	coll := seedUsers(t)
+
+	var users []user
+	page, err := mongodb.PaginateOffset(context.TODO(), coll, bson.M{"age": 25}, bson.D{{Key: "name", Value: 1}}, 2, 2, &users)
+	if err != nil {
+		t.Fatalf("PaginateOffset: %v", err)
+	}
+	if *page != (mongodb.OffsetPage{Number: 2, Size: 2, Total: 3, Pages: 2}) {
+		t.Errorf("page = %+v", page)
+	}
+	if len(users) != 1 || users[0].Id != "u2" {
+		t.Errorf("users = %+v", users)
+	}
+}
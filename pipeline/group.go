@@ -0,0 +1,161 @@
+package pipeline
+
+import "go.mongodb.org/mongo-driver/bson"
+
+// Accumulator computes an output field of Group, Bucket, BucketAuto or
+// SetWindowFields.
+type Accumulator struct {
+	name     string
+	operator string
+	expr     interface{}
+	window   *Window
+}
+
+// Accumulate is an accumulator for operator without a dedicated
+// constructor.
+func Accumulate(name, operator string, expr interface{}) Accumulator {
+	return Accumulator{name: name, operator: operator, expr: expr}
+}
+
+func Sum(name string, expr interface{}) Accumulator {
+	return Accumulate(name, "$sum", expr)
+}
+
+// Count counts the documents, like Sum(name, 1).
+func Count(name string) Accumulator {
+	return Sum(name, 1)
+}
+
+func Avg(name string, expr interface{}) Accumulator {
+	return Accumulate(name, "$avg", expr)
+}
+
+func Min(name string, expr interface{}) Accumulator {
+	return Accumulate(name, "$min", expr)
+}
+
+func Max(name string, expr interface{}) Accumulator {
+	return Accumulate(name, "$max", expr)
+}
+
+func First(name string, expr interface{}) Accumulator {
+	return Accumulate(name, "$first", expr)
+}
+
+func Last(name string, expr interface{}) Accumulator {
+	return Accumulate(name, "$last", expr)
+}
+
+func Push(name string, expr interface{}) Accumulator {
+	return Accumulate(name, "$push", expr)
+}
+
+func AddToSet(name string, expr interface{}) Accumulator {
+	return Accumulate(name, "$addToSet", expr)
+}
+
+// Rank, DenseRank and DocumentNumber are only valid in SetWindowFields.
+
+func Rank(name string) Accumulator {
+	return Accumulate(name, "$rank", bson.D{})
+}
+
+func DenseRank(name string) Accumulator {
+	return Accumulate(name, "$denseRank", bson.D{})
+}
+
+func DocumentNumber(name string) Accumulator {
+	return Accumulate(name, "$documentNumber", bson.D{})
+}
+
+// Window bounds the documents a window accumulator sees, either by position
+// (Documents) or by the value of the sortBy field (Range, in Unit for
+// dates). Bounds are numbers or "unbounded" and "current".
+type Window struct {
+	Documents []interface{}
+	Range     []interface{}
+	Unit      string
+}
+
+// Over restricts a SetWindowFields accumulator to w.
+func (a Accumulator) Over(w Window) Accumulator {
+	a.window = &w
+	return a
+}
+
+func (a Accumulator) elem() bson.E {
+	d := bson.D{{Key: a.operator, Value: a.expr}}
+	if w := a.window; w != nil {
+		var wd bson.D
+		if w.Documents != nil {
+			wd = append(wd, bson.E{Key: "documents", Value: bson.A(w.Documents)})
+		}
+		if w.Range != nil {
+			wd = append(wd, bson.E{Key: "range", Value: bson.A(w.Range)})
+		}
+		if w.Unit != "" {
+			wd = append(wd, bson.E{Key: "unit", Value: w.Unit})
+		}
+		d = append(d, bson.E{Key: "window", Value: wd})
+	}
+	return bson.E{Key: a.name, Value: d}
+}
+
+func fields(d bson.D, accs []Accumulator) bson.D {
+	for _, a := range accs {
+		d = append(d, a.elem())
+	}
+	return d
+}
+
+// Group groups documents by the id expression; nil groups all documents
+// together.
+func Group(id interface{}, accs ...Accumulator) bson.D {
+	return stage("$group", fields(bson.D{{Key: "_id", Value: id}}, accs))
+}
+
+// Bucket groups documents into ranges of groupBy between consecutive
+// boundaries. Documents outside the boundaries go to the bucket with id
+// defaultBucket, or cause an error if it is nil. Without accumulators each
+// bucket only counts its documents.
+func Bucket(groupBy interface{}, boundaries []interface{}, defaultBucket interface{}, accs ...Accumulator) bson.D {
+	d := bson.D{
+		{Key: "groupBy", Value: groupBy},
+		{Key: "boundaries", Value: bson.A(boundaries)},
+	}
+	if defaultBucket != nil {
+		d = append(d, bson.E{Key: "default", Value: defaultBucket})
+	}
+	if len(accs) > 0 {
+		d = append(d, bson.E{Key: "output", Value: fields(nil, accs)})
+	}
+	return stage("$bucket", d)
+}
+
+// BucketAuto groups documents into the given number of evenly filled
+// buckets.
+func BucketAuto(groupBy interface{}, buckets int, accs ...Accumulator) bson.D {
+	d := bson.D{
+		{Key: "groupBy", Value: groupBy},
+		{Key: "buckets", Value: buckets},
+	}
+	if len(accs) > 0 {
+		d = append(d, bson.E{Key: "output", Value: fields(nil, accs)})
+	}
+	return stage("$bucketAuto", d)
+}
+
+// SetWindowFields computes the accumulators over windows of documents.
+// partitionBy may be nil to use a single partition, and sortBy may be nil
+// unless an accumulator needs an order.
+func SetWindowFields(partitionBy interface{}, sortBy bson.D, accs ...Accumulator) bson.D {
+	var d bson.D
+	if partitionBy != nil {
+		d = append(d, bson.E{Key: "partitionBy", Value: partitionBy})
+	}
+	if sortBy != nil {
+		d = append(d, bson.E{Key: "sortBy", Value: sortBy})
+	}
+	d = append(d, bson.E{Key: "output", Value: fields(nil, accs)})
+	return stage("$setWindowFields", d)
+}
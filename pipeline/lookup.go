@@ -0,0 +1,97 @@
+package pipeline
+
+import "go.mongodb.org/mongo-driver/bson"
+
+// Lookup joins the documents of from whose foreignField equals localField
+// into the array field as.
+func Lookup(from, localField, foreignField, as string) bson.D {
+	return stage("$lookup", bson.D{
+		{Key: "from", Value: from},
+		{Key: "localField", Value: localField},
+		{Key: "foreignField", Value: foreignField},
+		{Key: "as", Value: as},
+	})
+}
+
+// LookupPipeline joins the results of running p on from into the array field
+// as. let binds variables from the input document for use in p; it may be
+// nil.
+func LookupPipeline(from string, let bson.D, p Pipeline, as string) bson.D {
+	d := bson.D{{Key: "from", Value: from}}
+	if let != nil {
+		d = append(d, bson.E{Key: "let", Value: let})
+	}
+	d = append(d, bson.E{Key: "pipeline", Value: p}, bson.E{Key: "as", Value: as})
+	return stage("$lookup", d)
+}
+
+// GraphLookupOption configures a GraphLookup stage.
+type GraphLookupOption func(*bson.D)
+
+func MaxDepth(n int) GraphLookupOption {
+	return func(d *bson.D) {
+		*d = append(*d, bson.E{Key: "maxDepth", Value: n})
+	}
+}
+
+// DepthField stores the recursion depth of each match in field.
+func DepthField(field string) GraphLookupOption {
+	return func(d *bson.D) {
+		*d = append(*d, bson.E{Key: "depthField", Value: field})
+	}
+}
+
+// RestrictSearch only follows documents matching filter.
+func RestrictSearch(filter interface{}) GraphLookupOption {
+	return func(d *bson.D) {
+		*d = append(*d, bson.E{Key: "restrictSearchWithMatch", Value: filter})
+	}
+}
+
+// GraphLookup recursively joins the documents of from, starting with the
+// value of startWith and following connectFromField to connectToField.
+func GraphLookup(from string, startWith interface{}, connectFromField, connectToField, as string, opts ...GraphLookupOption) bson.D {
+	d := bson.D{
+		{Key: "from", Value: from},
+		{Key: "startWith", Value: startWith},
+		{Key: "connectFromField", Value: connectFromField},
+		{Key: "connectToField", Value: connectToField},
+		{Key: "as", Value: as},
+	}
+	for _, opt := range opts {
+		opt(&d)
+	}
+	return stage("$graphLookup", d)
+}
+
+// UnwindOption configures an Unwind stage.
+type UnwindOption func(*bson.D)
+
+// PreserveNullAndEmpty keeps documents whose array is missing, null or
+// empty.
+func PreserveNullAndEmpty() UnwindOption {
+	return func(d *bson.D) {
+		*d = append(*d, bson.E{Key: "preserveNullAndEmptyArrays", Value: true})
+	}
+}
+
+// IncludeArrayIndex stores the element's array index in field.
+func IncludeArrayIndex(field string) UnwindOption {
+	return func(d *bson.D) {
+		*d = append(*d, bson.E{Key: "includeArrayIndex", Value: field})
+	}
+}
+
+// Unwind outputs one document per element of the array at path. The "$"
+// prefix of path is optional.
+func Unwind(path string, opts ...UnwindOption) bson.D {
+	if len(opts) == 0 {
+		return stage("$unwind", fieldPath(path))
+	}
+
+	d := bson.D{{Key: "path", Value: fieldPath(path)}}
+	for _, opt := range opts {
+		opt(&d)
+	}
+	return stage("$unwind", d)
+}
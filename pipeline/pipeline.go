@@ -0,0 +1,183 @@
+// Package pipeline builds aggregation pipelines for the mongodb package.
+//
+//	p := pipeline.New(
+//		pipeline.Match(filter.Eq("status", "paid")),
+//		pipeline.Group("$customerId",
+//			pipeline.Sum("total", "$amount"),
+//			pipeline.Count("orders"),
+//		),
+//		pipeline.Sort(bson.D{{Key: "total", Value: -1}}),
+//		pipeline.Limit(10),
+//	)
+//	err := coll.Aggregate(ctx, p, &top)
+//
+// Stages are plain bson.D values, so hand-written stages can be mixed in
+// freely.
+package pipeline
+
+import (
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Pipeline is a list of stages. It can be passed to Collection.Aggregate and
+// Database.Aggregate as is.
+type Pipeline []bson.D
+
+func New(stages ...bson.D) Pipeline {
+	return append(Pipeline{}, stages...)
+}
+
+// Then returns the pipeline extended with stages.
+func (p Pipeline) Then(stages ...bson.D) Pipeline {
+	return append(p[:len(p):len(p)], stages...)
+}
+
+// Mongo returns the pipeline as a mongo.Pipeline.
+func (p Pipeline) Mongo() mongo.Pipeline {
+	return mongo.Pipeline(append([]bson.D(nil), p...))
+}
+
+func stage(name string, value interface{}) bson.D {
+	return bson.D{{Key: name, Value: value}}
+}
+
+// fieldPath prefixes name with "$" unless it already is a field path.
+func fieldPath(name string) string {
+	if strings.HasPrefix(name, "$") {
+		return name
+	}
+	return "$" + name
+}
+
+// Match filters documents. filter may be any query, such as a filter.Filter.
+func Match(filter interface{}) bson.D {
+	return stage("$match", filter)
+}
+
+func Project(spec interface{}) bson.D {
+	return stage("$project", spec)
+}
+
+// AddFields adds or replaces fields with the values of expressions. It is
+// also available as $set.
+func AddFields(fields bson.D) bson.D {
+	return stage("$addFields", fields)
+}
+
+func Unset(fields ...string) bson.D {
+	return stage("$unset", fields)
+}
+
+func Sort(spec bson.D) bson.D {
+	return stage("$sort", spec)
+}
+
+func Skip(n int64) bson.D {
+	return stage("$skip", n)
+}
+
+func Limit(n int64) bson.D {
+	return stage("$limit", n)
+}
+
+func Sample(size int64) bson.D {
+	return stage("$sample", bson.D{{Key: "size", Value: size}})
+}
+
+// CountStage replaces the documents with one holding their number in field.
+// For counts per group, use the Count accumulator.
+func CountStage(field string) bson.D {
+	return stage("$count", field)
+}
+
+// ReplaceRoot replaces each document with the result of expr.
+func ReplaceRoot(expr interface{}) bson.D {
+	return stage("$replaceRoot", bson.D{{Key: "newRoot", Value: expr}})
+}
+
+// SortByCount groups documents by expr and sorts the groups by size.
+func SortByCount(expr interface{}) bson.D {
+	return stage("$sortByCount", expr)
+}
+
+// Facet runs several pipelines over the same input. Each pipeline's results
+// are stored in the field named by its key.
+func Facet(facets map[string]Pipeline) bson.D {
+	names := make([]string, 0, len(facets))
+	for name := range facets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	d := make(bson.D, len(names))
+	for i, name := range names {
+		d[i] = bson.E{Key: name, Value: facets[name]}
+	}
+	return stage("$facet", d)
+}
+
+// Out writes the results to coll, replacing its contents. It must be the
+// last stage.
+func Out(coll string) bson.D {
+	return stage("$out", coll)
+}
+
+// OutToDatabase is Out with a collection in another database.
+func OutToDatabase(db, coll string) bson.D {
+	return stage("$out", bson.D{{Key: "db", Value: db}, {Key: "coll", Value: coll}})
+}
+
+// MergeOption configures a Merge stage.
+type MergeOption func(*bson.D)
+
+// MergeOn sets the fields identifying matching documents. Defaults to _id.
+func MergeOn(fields ...string) MergeOption {
+	return func(d *bson.D) {
+		*d = append(*d, bson.E{Key: "on", Value: fields})
+	}
+}
+
+// WhenMatched sets the action for results matching an existing document:
+// "replace", "keepExisting", "merge", "fail", or a Pipeline.
+func WhenMatched(action interface{}) MergeOption {
+	return func(d *bson.D) {
+		*d = append(*d, bson.E{Key: "whenMatched", Value: action})
+	}
+}
+
+// WhenNotMatched sets the action for results matching no document:
+// "insert", "discard" or "fail".
+func WhenNotMatched(action string) MergeOption {
+	return func(d *bson.D) {
+		*d = append(*d, bson.E{Key: "whenNotMatched", Value: action})
+	}
+}
+
+// MergeLet sets the variables available to a WhenMatched pipeline.
+func MergeLet(vars bson.D) MergeOption {
+	return func(d *bson.D) {
+		*d = append(*d, bson.E{Key: "let", Value: vars})
+	}
+}
+
+// Merge writes the results into coll. It must be the last stage.
+func Merge(coll string, opts ...MergeOption) bson.D {
+	return merge(coll, opts)
+}
+
+// MergeToDatabase is Merge with a collection in another database.
+func MergeToDatabase(db, coll string, opts ...MergeOption) bson.D {
+	return merge(bson.D{{Key: "db", Value: db}, {Key: "coll", Value: coll}}, opts)
+}
+
+func merge(into interface{}, opts []MergeOption) bson.D {
+	d := bson.D{{Key: "into", Value: into}}
+	for _, opt := range opts {
+		opt(&d)
+	}
+	return stage("$merge", d)
+}
@@ -0,0 +1,96 @@
+package pipeline_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/subratohld/mongodb/filter"
+	"github.com/subratohld/mongodb/mongodbtest"
+	"github.com/subratohld/mongodb/pipeline"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestStages(t *testing.T) {
+	tests := []struct {
+		name  string
+		stage bson.D
+		want  bson.D
+	}{
+		{"group", pipeline.Group("$customerId", pipeline.Sum("total", "$amount"), pipeline.Count("orders")), bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$customerId"},
+			{Key: "total", Value: bson.D{{Key: "$sum", Value: "$amount"}}},
+			{Key: "orders", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}}},
+		{"unwind", pipeline.Unwind("items"), bson.D{{Key: "$unwind", Value: "$items"}}},
+		{"unwind options", pipeline.Unwind("$items", pipeline.PreserveNullAndEmpty()), bson.D{{Key: "$unwind", Value: bson.D{
+			{Key: "path", Value: "$items"},
+			{Key: "preserveNullAndEmptyArrays", Value: true},
+		}}}},
+		{"lookup pipeline", pipeline.LookupPipeline("items", bson.D{{Key: "id", Value: "$_id"}}, pipeline.New(pipeline.Limit(1)), "first"), bson.D{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: "items"},
+			{Key: "let", Value: bson.D{{Key: "id", Value: "$_id"}}},
+			{Key: "pipeline", Value: pipeline.Pipeline{{{Key: "$limit", Value: int64(1)}}}},
+			{Key: "as", Value: "first"},
+		}}}},
+		{"bucket", pipeline.Bucket("$price", []interface{}{0, 100}, "other"), bson.D{{Key: "$bucket", Value: bson.D{
+			{Key: "groupBy", Value: "$price"},
+			{Key: "boundaries", Value: bson.A{0, 100}},
+			{Key: "default", Value: "other"},
+		}}}},
+		{"window", pipeline.SetWindowFields("$state", bson.D{{Key: "date", Value: 1}},
+			pipeline.Sum("running", "$qty").Over(pipeline.Window{Documents: []interface{}{"unbounded", "current"}})), bson.D{{Key: "$setWindowFields", Value: bson.D{
+			{Key: "partitionBy", Value: "$state"},
+			{Key: "sortBy", Value: bson.D{{Key: "date", Value: 1}}},
+			{Key: "output", Value: bson.D{{Key: "running", Value: bson.D{
+				{Key: "$sum", Value: "$qty"},
+				{Key: "window", Value: bson.D{{Key: "documents", Value: bson.A{"unbounded", "current"}}}},
+			}}}},
+		}}}},
+		{"facet", pipeline.Facet(map[string]pipeline.Pipeline{"b": pipeline.New(pipeline.CountStage("n")), "a": pipeline.New()}), bson.D{{Key: "$facet", Value: bson.D{
+			{Key: "a", Value: pipeline.Pipeline{}},
+			{Key: "b", Value: pipeline.Pipeline{{{Key: "$count", Value: "n"}}}},
+		}}}},
+		{"merge", pipeline.Merge("totals", pipeline.MergeOn("day"), pipeline.WhenMatched("replace")), bson.D{{Key: "$merge", Value: bson.D{
+			{Key: "into", Value: "totals"},
+			{Key: "on", Value: []string{"day"}},
+			{Key: "whenMatched", Value: "replace"},
+		}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.stage, tt.want) {
+				t.Errorf("stage = %v, want %v", tt.stage, tt.want)
+			}
+		})
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	ctx := context.TODO()
+	coll := mongodbtest.NewClient().Database("testdb").Collection("orders")
+	_, err := coll.InsertMany(ctx, []interface{}{
+		bson.M{"_id": 1, "status": "paid", "amount": 30},
+		bson.M{"_id": 2, "status": "paid", "amount": 50},
+		bson.M{"_id": 3, "status": "open", "amount": 70},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := pipeline.New(pipeline.Match(filter.Eq("status", "paid"))).
+		Then(pipeline.Sort(bson.D{{Key: "amount", Value: -1}}), pipeline.Limit(1), pipeline.Project(bson.D{{Key: "amount", Value: 1}}))
+
+	var got []bson.M
+	if err := coll.Aggregate(ctx, p, &got); err != nil {
+		t.Fatalf("Aggregate: %v", err)
+	}
+	if want := []bson.M{{"_id": int32(2), "amount": int32(50)}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Aggregate = %v, want %v", got, want)
+	}
+
+	if err := coll.Aggregate(ctx, p.Mongo(), &got); err != nil || len(got) != 1 {
+		t.Errorf("Aggregate with mongo.Pipeline = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/subratohld/mongodb/internal/structtag"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const tagName = "mongodb"
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	dateTimeType = reflect.TypeOf(primitive.DateTime(0))
+	objectIDType = reflect.TypeOf(primitive.ObjectID{})
+)
+
+// field is a struct field mapped to a document field.
+type field struct {
+	name  string
+	index []int
+}
+
+// fieldName returns the document field name, or "" if f is nil.
+func (f *field) fieldName() string {
+	if f == nil {
+		return ""
+	}
+	return f.name
+}
+
+type indexSpec struct {
+	name   string
+	keys   bson.D
+	unique bool
+}
+
+func (ix indexSpec) model() mongo.IndexModel {
+	opts := options.Index()
+	if ix.name != "" {
+		opts.SetName(ix.name)
+	}
+	if ix.unique {
+		opts.SetUnique(true)
+	}
+	return mongo.IndexModel{Keys: ix.keys, Options: opts}
+}
+
+type metadata struct {
+	collection string
+	id         field
+	createdAt  *field
+	updatedAt  *field
+	version    *field
+	indexes    []indexSpec
+}
+
+// parse reads the metadata of struct type t from its `mongodb` tags.
+func parse(t reflect.Type) (*metadata, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("repository: %s is not a struct", t)
+	}
+
+	md := &metadata{collection: strings.ToLower(t.Name())}
+	var bsonID *field
+	named := make(map[string]int)
+
+	for i := 0; i < t.NumField(); i++ {
+		if sf := t.Field(i); sf.Name == "_" {
+			if name, ok := structtag.Lookup(sf.Tag.Get(tagName), "collection"); ok {
+				md.collection = name
+			}
+		}
+	}
+
+	for _, tf := range structtag.Fields(t) {
+		sf, name := tf.StructField, tf.Name
+		f := field{name: name, index: tf.Index}
+		if name == "_id" {
+			bsonID = &f
+		}
+		if !tf.Tagged {
+			continue
+		}
+
+		var index *indexSpec
+		for _, opt := range tf.Options() {
+			key, value, _ := strings.Cut(opt, "=")
+			switch key {
+			case "id":
+				md.id = f
+			case "createdAt", "updatedAt":
+				if sf.Type != timeType && sf.Type != dateTimeType {
+					return nil, fmt.Errorf("repository: %s.%s must be a time.Time or primitive.DateTime", t.Name(), sf.Name)
+				}
+				if key == "createdAt" {
+					md.createdAt = &f
+				} else {
+					md.updatedAt = &f
+				}
+			case "version":
+				switch sf.Type.Kind() {
+				case reflect.Int, reflect.Int32, reflect.Int64:
+				default:
+					return nil, fmt.Errorf("repository: %s.%s must be an integer", t.Name(), sf.Name)
+				}
+				md.version = &f
+			case "index", "unique":
+				if index == nil {
+					index = md.index(named, value)
+					index.keys = append(index.keys, bson.E{Key: name, Value: 1})
+				}
+				if key == "unique" {
+					index.unique = true
+				}
+			case "":
+			default:
+				return nil, fmt.Errorf("repository: unknown option %q on %s.%s", key, t.Name(), sf.Name)
+			}
+		}
+	}
+
+	if md.id.name == "" {
+		if bsonID == nil {
+			return nil, fmt.Errorf("repository: %s has no id field", t.Name())
+		}
+		md.id = *bsonID
+	}
+	if md.id.name != "_id" {
+		return nil, fmt.Errorf("repository: the id field of %s must be stored as _id", t.Name())
+	}
+	return md, nil
+}
+
+// index returns the index called name, creating it if needed. Fields
+// naming the same index form a compound index in field order; unnamed
+// indexes always cover a single field.
+func (md *metadata) index(named map[string]int, name string) *indexSpec {
+	if name != "" {
+		if i, ok := named[name]; ok {
+			return &md.indexes[i]
+		}
+		named[name] = len(md.indexes)
+	}
+	md.indexes = append(md.indexes, indexSpec{name: name})
+	return &md.indexes[len(md.indexes)-1]
+}
+
+func setTime(v reflect.Value, t time.Time) {
+	if v.Type() == dateTimeType {
+		v.Set(reflect.ValueOf(primitive.NewDateTimeFromTime(t)))
+		return
+	}
+	v.Set(reflect.ValueOf(t))
+}
@@ -0,0 +1,256 @@
+// Package repository provides CRUD repositories for document types
+// described by struct tags.
+//
+//	type User struct {
+//		_         struct{}  `mongodb:"collection=users"`
+//		ID        string    `bson:"_id,omitempty" mongodb:"id"`
+//		Email     string    `bson:"email" mongodb:"unique"`
+//		Org       string    `bson:"org" mongodb:"index=org_name"`
+//		Name      string    `bson:"name" mongodb:"index=org_name"`
+//		CreatedAt time.Time `bson:"createdAt" mongodb:"createdAt"`
+//		UpdatedAt time.Time `bson:"updatedAt" mongodb:"updatedAt"`
+//	}
+//
+//	users, err := repository.New[User](db)
+//
+// The `mongodb` tag options are:
+//
+//	collection=<name>  on a blank field: the collection name; defaults to the lower-cased type name
+//	id                 the document id, stored as _id; defaults to the field stored as _id
+//	index[=<name>]     an ascending index; fields sharing a name form a compound index
+//	unique             makes the field's index unique, creating one if needed
+//	createdAt          set on insert (time.Time or primitive.DateTime)
+//	updatedAt          set on every write (time.Time or primitive.DateTime)
+//	version            an integer for optimistic locking, see mongodb.WithVersion
+package repository
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/subratohld/mongodb"
+	"github.com/subratohld/mongodb/internal/clock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Repository stores values of T in a collection. Methods looking up a single
+// document by id return mongo.ErrNoDocuments when there is none.
+type Repository[T any] interface {
+	Collection() mongodb.Collection
+	// EnsureIndexes creates the indexes declared by the tags of T.
+	EnsureIndexes(ctx context.Context) error
+	Get(ctx context.Context, id interface{}) (*T, error)
+	List(ctx context.Context, filter interface{}, opts ...*options.FindOptions) ([]T, error)
+	// Create inserts doc. A zero string or ObjectID id is generated, and
+	// the id and timestamps are written back to doc.
+	Create(ctx context.Context, doc *T) error
+	// Update sets the fields of the stored document with doc's id to those
+	// of doc, except the creation time. Fields that doc omits through
+	// omitempty keep their stored value. With a version field, the stored
+	// version must equal doc's or the update fails with
+	// mongodb.ErrVersionConflict; on success doc's version is incremented.
+	Update(ctx context.Context, doc *T) error
+	// Upsert is Update that inserts doc if it is not stored yet.
+	Upsert(ctx context.Context, doc *T) error
+	Delete(ctx context.Context, id interface{}) error
+	Exists(ctx context.Context, filter interface{}) (bool, error)
+	Count(ctx context.Context, filter interface{}) (int64, error)
+}
+
+// New returns the repository of T in db. It fails if the tags of T are
+// invalid.
+func New[T any](db mongodb.Database) (Repository[T], error) {
+	md, err := parse(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []mongodb.CollectionOption
+	if md.createdAt != nil || md.updatedAt != nil {
+		opts = append(opts, mongodb.WithTimestamps(md.createdAt.fieldName(), md.updatedAt.fieldName()))
+	}
+	if md.version != nil {
+		opts = append(opts, mongodb.WithVersion(md.version.name))
+	}
+
+	coll := db.CollectionWith(md.collection, opts...)
+	return &repository[T]{
+		md:    md,
+		coll:  coll,
+		typed: mongodb.NewTypedCollection[T](coll),
+	}, nil
+}
+
+type repository[T any] struct {
+	md    *metadata
+	coll  mongodb.Collection
+	typed mongodb.TypedCollection[T]
+}
+
+func (r *repository[T]) Collection() mongodb.Collection {
+	return r.coll
+}
+
+func (r *repository[T]) EnsureIndexes(ctx context.Context) error {
+	if len(r.md.indexes) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.IndexModel, len(r.md.indexes))
+	for i, ix := range r.md.indexes {
+		models[i] = ix.model()
+	}
+	_, err := r.coll.Indexes().CreateMany(ctx, models)
+	return err
+}
+
+func (r *repository[T]) Get(ctx context.Context, id interface{}) (*T, error) {
+	return r.typed.FindOne(ctx, byID(id))
+}
+
+func (r *repository[T]) List(ctx context.Context, filter interface{}, opts ...*options.FindOptions) ([]T, error) {
+	if filter == nil {
+		filter = bson.D{}
+	}
+	return r.typed.Find(ctx, filter, opts...)
+}
+
+func (r *repository[T]) Create(ctx context.Context, doc *T) error {
+	v := reflect.ValueOf(doc).Elem()
+	idv := v.FieldByIndex(r.md.id.index)
+	generateID(idv)
+
+	// The collection stamps the document; fixing the time lets doc get the
+	// same values.
+	t := clock.Now()
+	id, err := r.coll.InsertOne(clock.With(ctx, t), doc)
+	if err != nil {
+		return err
+	}
+	if idv.IsZero() {
+		if rid := reflect.ValueOf(id); rid.IsValid() && rid.Type().AssignableTo(idv.Type()) {
+			idv.Set(rid)
+		}
+	}
+	r.stamp(v, r.md.createdAt, t)
+	r.stamp(v, r.md.updatedAt, t)
+	if f := r.md.version; f != nil && v.FieldByIndex(f.index).IsZero() {
+		v.FieldByIndex(f.index).SetInt(1)
+	}
+	return nil
+}
+
+func (r *repository[T]) Update(ctx context.Context, doc *T) error {
+	res, err := r.update(ctx, doc, false)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *repository[T]) Upsert(ctx context.Context, doc *T) error {
+	generateID(reflect.ValueOf(doc).Elem().FieldByIndex(r.md.id.index))
+	_, err := r.update(ctx, doc, true)
+	return err
+}
+
+func (r *repository[T]) update(ctx context.Context, doc *T, upsert bool) (*mongo.UpdateResult, error) {
+	v := reflect.ValueOf(doc).Elem()
+	t := clock.Now()
+
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var fields bson.D
+	if err := bson.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+
+	var filter bson.D
+	set := make(bson.D, 0, len(fields))
+	for _, e := range fields {
+		switch {
+		case e.Key == "_id":
+			filter = append(byID(e.Value), filter...)
+		case e.Key == r.md.version.fieldName():
+			filter = append(filter, e)
+		case e.Key == r.md.createdAt.fieldName(), e.Key == r.md.updatedAt.fieldName():
+			// The collection stamps the timestamps and increments the
+			// version.
+		default:
+			set = append(set, e)
+		}
+	}
+	if len(filter) == 0 || filter[0].Key != "_id" {
+		return nil, errors.New("repository: the document has no id")
+	}
+
+	u := bson.D{{Key: "$set", Value: set}}
+	res, err := r.coll.UpdateOne(clock.With(ctx, t), filter, u, options.Update().SetUpsert(upsert))
+	if err != nil {
+		return nil, err
+	}
+	if res.MatchedCount+res.UpsertedCount > 0 {
+		r.stamp(v, r.md.updatedAt, t)
+	}
+	if res.UpsertedCount > 0 {
+		r.stamp(v, r.md.createdAt, t)
+	}
+	if f := r.md.version; f != nil && res.MatchedCount+res.UpsertedCount > 0 {
+		fv := v.FieldByIndex(f.index)
+		fv.SetInt(fv.Int() + 1)
+	}
+	return res, nil
+}
+
+func (r *repository[T]) Delete(ctx context.Context, id interface{}) error {
+	res, err := r.coll.DeleteOne(ctx, byID(id))
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *repository[T]) Exists(ctx context.Context, filter interface{}) (bool, error) {
+	n, err := r.coll.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	return n > 0, err
+}
+
+func (r *repository[T]) Count(ctx context.Context, filter interface{}) (int64, error) {
+	return r.coll.CountDocuments(ctx, filter)
+}
+
+func (r *repository[T]) stamp(v reflect.Value, f *field, t time.Time) {
+	if f != nil {
+		setTime(v.FieldByIndex(f.index), t)
+	}
+}
+
+func byID(id interface{}) bson.D {
+	return bson.D{{Key: "_id", Value: id}}
+}
+
+// generateID sets a zero ObjectID or string id to a new ObjectID.
+func generateID(v reflect.Value) {
+	if !v.IsZero() {
+		return
+	}
+	switch {
+	case v.Type() == objectIDType:
+		v.Set(reflect.ValueOf(primitive.NewObjectID()))
+	case v.Kind() == reflect.String:
+		v.SetString(primitive.NewObjectID().Hex())
+	}
+}
@@ -0,0 +1,153 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/subratohld/mongodb"
+	"github.com/subratohld/mongodb/mongodbtest"
+	"github.com/subratohld/mongodb/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type User struct {
+	_         struct{}  `mongodb:"collection=people"`
+	ID        string    `bson:"_id,omitempty" mongodb:"id"`
+	Email     string    `bson:"email" mongodb:"unique"`
+	Org       string    `bson:"org" mongodb:"index=org_name"`
+	Name      string    `bson:"name" mongodb:"index=org_name"`
+	CreatedAt time.Time `bson:"createdAt" mongodb:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" mongodb:"updatedAt"`
+}
+
+func TestRepository(t *testing.T) {
+	ctx := context.TODO()
+	db := mongodbtest.NewClient().Database("testdb")
+
+	users, err := repository.New[User](db)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if name := users.Collection().Name(); name != "people" {
+		t.Errorf("collection = %q, want people", name)
+	}
+	if err := users.EnsureIndexes(ctx); err != nil {
+		t.Fatalf("EnsureIndexes: %v", err)
+	}
+	specs, err := users.Collection().Indexes().ListSpecifications(ctx)
+	if err != nil || len(specs) != 3 || specs[2].Name != "org_name" {
+		t.Fatalf("indexes = %v, %v", specs, err)
+	}
+
+	priya := User{Email: "priya@example.com", Org: "acme", Name: "Priya"}
+	if err := users.Create(ctx, &priya); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if priya.ID == "" || priya.CreatedAt.IsZero() || !priya.UpdatedAt.Equal(priya.CreatedAt) {
+		t.Errorf("Create did not set the id and timestamps: %+v", priya)
+	}
+	if err := users.Create(ctx, &User{Email: "priya@example.com"}); !mongo.IsDuplicateKeyError(err) {
+		t.Errorf("Create with a duplicate email = %v", err)
+	}
+
+	got, err := users.Get(ctx, priya.ID)
+	if err != nil || got.Name != "Priya" || !got.CreatedAt.Equal(priya.CreatedAt) {
+		t.Fatalf("Get = %+v, %v", got, err)
+	}
+
+	update := User{ID: priya.ID, Email: priya.Email, Org: "acme", Name: "Priya S"}
+	if err := users.Update(ctx, &update); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got, _ := users.Get(ctx, priya.ID); got.Name != "Priya S" || !got.CreatedAt.Equal(priya.CreatedAt) || !got.UpdatedAt.Equal(update.UpdatedAt) {
+		t.Errorf("after Update = %+v, doc = %+v", got, update)
+	}
+	if err := users.Update(ctx, &User{ID: "missing"}); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("Update of a missing document = %v", err)
+	}
+
+	shekhar := User{Email: "shekhar@example.com", Org: "acme", Name: "Shekhar"}
+	if err := users.Upsert(ctx, &shekhar); err != nil || shekhar.ID == "" || shekhar.CreatedAt.IsZero() {
+		t.Fatalf("Upsert = %+v, %v", shekhar, err)
+	}
+	if got, _ := users.Get(ctx, shekhar.ID); !got.CreatedAt.Equal(shekhar.CreatedAt) || !got.UpdatedAt.Equal(shekhar.UpdatedAt) {
+		t.Errorf("after Upsert = %+v, doc = %+v", got, shekhar)
+	}
+
+	list, err := users.List(ctx, bson.M{"org": "acme"})
+	if err != nil || len(list) != 2 {
+		t.Errorf("List = %+v, %v", list, err)
+	}
+	if n, err := users.Count(ctx, bson.M{}); err != nil || n != 2 {
+		t.Errorf("Count = %d, %v", n, err)
+	}
+
+	if err := users.Delete(ctx, priya.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if ok, err := users.Exists(ctx, bson.M{"_id": priya.ID}); err != nil || ok {
+		t.Errorf("Exists after Delete = %v, %v", ok, err)
+	}
+	if err := users.Delete(ctx, priya.ID); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("second Delete = %v", err)
+	}
+}
+
+func TestRepositoryVersion(t *testing.T) {
+	type Doc struct {
+		ID      primitive.ObjectID `bson:"_id,omitempty"`
+		Title   string             `bson:"title"`
+		Version int                `bson:"version" mongodb:"version"`
+	}
+
+	ctx := context.TODO()
+	docs, err := repository.New[Doc](mongodbtest.NewClient().Database("testdb"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	doc := Doc{Title: "draft"}
+	if err := docs.Create(ctx, &doc); err != nil || doc.ID.IsZero() || doc.Version != 1 {
+		t.Fatalf("Create = %+v, %v", doc, err)
+	}
+
+	stale := doc
+	doc.Title = "final"
+	if err := docs.Update(ctx, &doc); err != nil || doc.Version != 2 {
+		t.Fatalf("Update = %+v, %v", doc, err)
+	}
+	if err := docs.Update(ctx, &stale); !errors.Is(err, mongodb.ErrVersionConflict) {
+		t.Errorf("Update with a stale version = %v", err)
+	}
+	if got, err := docs.Get(ctx, doc.ID); err != nil || got.Title != "final" || got.Version != 2 {
+		t.Errorf("Get = %+v, %v", got, err)
+	}
+}
+
+func TestInvalidTags(t *testing.T) {
+	type noID struct {
+		Name string `bson:"name"`
+	}
+	type badTime struct {
+		ID      string `bson:"_id"`
+		Created string `mongodb:"createdAt"`
+	}
+	type unknown struct {
+		ID string `bson:"_id" mongodb:"primary"`
+	}
+
+	db := mongodbtest.NewClient().Database("testdb")
+	if _, err := repository.New[noID](db); err == nil {
+		t.Error("New accepted a type without an id")
+	}
+	if _, err := repository.New[badTime](db); err == nil {
+		t.Error("New accepted a string timestamp")
+	}
+	if _, err := repository.New[unknown](db); err == nil {
+		t.Error("New accepted an unknown option")
+	}
+}
@@ -23,7 +23,7 @@ type User struct {
 }
 
 func TestInsertOne(t *testing.T) {
-	client, err := mongodb.NewClient(context.TODO(), URI)
+	client, err := mongodb.NewClient(context.TODO(), mongodb.WithURI(URI))
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -39,7 +39,7 @@ func TestInsertOne(t *testing.T) {
 }
 
 func TestInsertMany(t *testing.T) {
-	client, err := mongodb.NewClient(context.TODO(), URI)
+	client, err := mongodb.NewClient(context.TODO(), mongodb.WithURI(URI))
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -61,7 +61,7 @@ func TestInsertMany(t *testing.T) {
 }
 
 func TestFindOne(t *testing.T) {
-	client, err := mongodb.NewClient(context.TODO(), URI)
+	client, err := mongodb.NewClient(context.TODO(), mongodb.WithURI(URI))
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -83,7 +83,7 @@ func TestFindOne(t *testing.T) {
 }
 
 func TestFindSorting(t *testing.T) {
-	client, err := mongodb.NewClient(context.TODO(), URI)
+	client, err := mongodb.NewClient(context.TODO(), mongodb.WithURI(URI))
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -99,21 +99,21 @@ func TestFindSorting(t *testing.T) {
 
 	opts := options.Find()
 
-	opts.SetSort(bson.D{{"name", 1}}) // Ascending order
+	opts.SetSort(bson.D{{Key: "name", Value: 1}}) // Ascending order
 	err = coll.Find(context.TODO(), bson.M{}, &results, opts)
 	printRes(err, results)
 
-	opts.SetSort(bson.D{{"age", -1}}) // Descending order
+	opts.SetSort(bson.D{{Key: "age", Value: -1}}) // Descending order
 	err = coll.Find(context.TODO(), bson.M{}, &results, opts)
 	printRes(err, results)
 
-	opts.SetSort(bson.D{{"age", -1}, {"name", 1}})
+	opts.SetSort(bson.D{{Key: "age", Value: -1}, {Key: "name", Value: 1}})
 	err = coll.Find(context.TODO(), bson.M{}, &results, opts)
 	printRes(err, results)
 }
 
 func TestFindProjection(t *testing.T) {
-	client, err := mongodb.NewClient(context.TODO(), URI)
+	client, err := mongodb.NewClient(context.TODO(), mongodb.WithURI(URI))
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -129,15 +129,15 @@ func TestFindProjection(t *testing.T) {
 
 	opts := options.Find()
 
-	opts.SetProjection(bson.D{{"_id", -1}})
+	opts.SetProjection(bson.D{{Key: "_id", Value: -1}})
 	err = coll.Find(context.TODO(), bson.M{}, &results, opts)
 	printRes(err, results)
 
-	opts.SetProjection(bson.D{{"age", 1}})
+	opts.SetProjection(bson.D{{Key: "age", Value: 1}})
 	err = coll.Find(context.TODO(), bson.M{}, &results, opts)
 	printRes(err, results)
 
-	opts.SetProjection(bson.D{{"name", 1}})
+	opts.SetProjection(bson.D{{Key: "name", Value: 1}})
 	err = coll.Find(context.TODO(), bson.M{}, &results, opts)
 	printRes(err, results)
 }
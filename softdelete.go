@@ -0,0 +1,195 @@
+package mongodb
+
+import (
+	"context"
+
+	"github.com/subratohld/mongodb/internal/bsonutil"
+	"github.com/subratohld/mongodb/internal/clock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SoftDeleter is implemented by collections opened with WithSoftDelete.
+type SoftDeleter interface {
+	// Restore undeletes the deleted documents matching filter.
+	Restore(ctx context.Context, filter interface{}) (*mongo.UpdateResult, error)
+	// Purge permanently removes the deleted documents matching filter.
+	Purge(ctx context.Context, filter interface{}) (*mongo.DeleteResult, error)
+}
+
+type deletedScope int
+
+const (
+	excludeDeleted deletedScope = iota
+	includeDeleted
+	onlyDeleted
+)
+
+type deletedScopeKey struct{}
+
+// WithDeleted returns a context under which soft-delete collections also
+// see deleted documents.
+func WithDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, deletedScopeKey{}, includeDeleted)
+}
+
+// OnlyDeleted returns a context under which soft-delete collections only see
+// deleted documents.
+func OnlyDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, deletedScopeKey{}, onlyDeleted)
+}
+
+type softDeleteCollection struct {
+	Collection
+	field string
+}
+
+func (sc *softDeleteCollection) deleted(exists bool) bson.D {
+	return bson.D{{Key: sc.field, Value: bson.D{{Key: "$exists", Value: exists}}}}
+}
+
+func and(filter interface{}, cond bson.D) bson.D {
+	return bson.D{{Key: "$and", Value: bson.A{orEmpty(filter), cond}}}
+}
+
+// scope restricts filter to the documents visible under ctx.
+func (sc *softDeleteCollection) scope(ctx context.Context, filter interface{}) interface{} {
+	scope, _ := ctx.Value(deletedScopeKey{}).(deletedScope)
+	switch scope {
+	case includeDeleted:
+		return filter
+	case onlyDeleted:
+		return and(filter, sc.deleted(true))
+	}
+	return and(filter, sc.deleted(false))
+}
+
+func (sc *softDeleteCollection) markDeleted(ctx context.Context) bson.D {
+	return bson.D{{Key: "$set", Value: bson.D{{Key: sc.field, Value: clock.From(ctx)}}}}
+}
+
+func (sc *softDeleteCollection) Clone(opts ...*options.CollectionOptions) (Collection, error) {
+	c, err := sc.Collection.Clone(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &softDeleteCollection{Collection: c, field: sc.field}, nil
+}
+
+func (sc *softDeleteCollection) Restore(ctx context.Context, filter interface{}) (*mongo.UpdateResult, error) {
+	return sc.Collection.UpdateMany(ctx, and(filter, sc.deleted(true)), bson.D{{Key: "$unset", Value: bson.D{{Key: sc.field, Value: ""}}}})
+}
+
+func (sc *softDeleteCollection) Purge(ctx context.Context, filter interface{}) (*mongo.DeleteResult, error) {
+	return sc.Collection.DeleteMany(ctx, and(filter, sc.deleted(true)))
+}
+
+func (sc *softDeleteCollection) DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	res, err := sc.Collection.UpdateOne(ctx, and(filter, sc.deleted(false)), sc.markDeleted(ctx), deleteToUpdate(opts))
+	if err != nil {
+		return nil, err
+	}
+	return &mongo.DeleteResult{DeletedCount: res.ModifiedCount}, nil
+}
+
+func (sc *softDeleteCollection) DeleteMany(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	res, err := sc.Collection.UpdateMany(ctx, and(filter, sc.deleted(false)), sc.markDeleted(ctx), deleteToUpdate(opts))
+	if err != nil {
+		return nil, err
+	}
+	return &mongo.DeleteResult{DeletedCount: res.ModifiedCount}, nil
+}
+
+func (sc *softDeleteCollection) FindOneAndDelete(ctx context.Context, filter interface{}, target interface{}, opts ...*options.FindOneAndDeleteOptions) error {
+	o := options.MergeFindOneAndDeleteOptions(opts...)
+	uo := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+	uo.Collation, uo.MaxTime, uo.Projection, uo.Sort, uo.Hint = o.Collation, o.MaxTime, o.Projection, o.Sort, o.Hint
+	return sc.Collection.FindOneAndUpdate(ctx, and(filter, sc.deleted(false)), sc.markDeleted(ctx), target, uo)
+}
+
+func deleteToUpdate(opts []*options.DeleteOptions) *options.UpdateOptions {
+	o := options.MergeDeleteOptions(opts...)
+	uo := options.Update()
+	uo.Collation, uo.Hint = o.Collation, o.Hint
+	return uo
+}
+
+func (sc *softDeleteCollection) UpdateByID(ctx context.Context, id interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return sc.UpdateOne(ctx, bson.D{{Key: "_id", Value: id}}, update, opts...)
+}
+
+func (sc *softDeleteCollection) UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return sc.Collection.UpdateOne(ctx, sc.scope(ctx, filter), update, opts...)
+}
+
+func (sc *softDeleteCollection) UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return sc.Collection.UpdateMany(ctx, sc.scope(ctx, filter), update, opts...)
+}
+
+func (sc *softDeleteCollection) ReplaceOne(ctx context.Context, filter interface{}, replacement interface{}, opts ...*options.ReplaceOptions) (*mongo.UpdateResult, error) {
+	return sc.Collection.ReplaceOne(ctx, sc.scope(ctx, filter), replacement, opts...)
+}
+
+func (sc *softDeleteCollection) FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, target interface{}, opts ...*options.FindOneAndUpdateOptions) error {
+	return sc.Collection.FindOneAndUpdate(ctx, sc.scope(ctx, filter), update, target, opts...)
+}
+
+func (sc *softDeleteCollection) FindOneAndReplace(ctx context.Context, filter interface{}, replace interface{}, target interface{}, opts ...*options.FindOneAndReplaceOptions) error {
+	return sc.Collection.FindOneAndReplace(ctx, sc.scope(ctx, filter), replace, target, opts...)
+}
+
+func (sc *softDeleteCollection) FindOne(ctx context.Context, filter interface{}, result interface{}, opts ...*options.FindOneOptions) error {
+	return sc.Collection.FindOne(ctx, sc.scope(ctx, filter), result, opts...)
+}
+
+func (sc *softDeleteCollection) Find(ctx context.Context, filter interface{}, results interface{}, opts ...*options.FindOptions) error {
+	return sc.Collection.Find(ctx, sc.scope(ctx, filter), results, opts...)
+}
+
+func (sc *softDeleteCollection) FindIterator(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (Iterator, error) {
+	return sc.Collection.FindIterator(ctx, sc.scope(ctx, filter), opts...)
+}
+
+func (sc *softDeleteCollection) FindEach(ctx context.Context, filter interface{}, fn func(decode func(v interface{}) error) error, opts ...*options.FindOptions) error {
+	return sc.Collection.FindEach(ctx, sc.scope(ctx, filter), fn, opts...)
+}
+
+func (sc *softDeleteCollection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	return sc.Collection.CountDocuments(ctx, sc.scope(ctx, filter), opts...)
+}
+
+func (sc *softDeleteCollection) Distinct(ctx context.Context, fieldName string, filter interface{}, opts ...*options.DistinctOptions) ([]interface{}, error) {
+	return sc.Collection.Distinct(ctx, fieldName, sc.scope(ctx, filter), opts...)
+}
+
+func (sc *softDeleteCollection) Aggregate(ctx context.Context, pipeline interface{}, target interface{}, opts ...*options.AggregateOptions) error {
+	p, err := sc.scopePipeline(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	return sc.Collection.Aggregate(ctx, p, target, opts...)
+}
+
+func (sc *softDeleteCollection) AggregateIterator(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (Iterator, error) {
+	p, err := sc.scopePipeline(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	return sc.Collection.AggregateIterator(ctx, p, opts...)
+}
+
+// scopePipeline starts pipeline with a $match stage applying the scope of
+// ctx.
+func (sc *softDeleteCollection) scopePipeline(ctx context.Context, pipeline interface{}) (interface{}, error) {
+	match := sc.scope(ctx, nil)
+	if match == nil {
+		return pipeline, nil
+	}
+
+	stages, err := bsonutil.ToDocs(pipeline)
+	if err != nil {
+		return nil, err
+	}
+	return append(mongo.Pipeline{{{Key: "$match", Value: match}}}, stages...), nil
+}
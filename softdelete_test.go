@@ -0,0 +1,122 @@
+package mongodb_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/subratohld/mongodb"
+	"github.com/subratohld/mongodb/mongodbtest"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestSoftDelete(t *testing.T) {
+	ctx := context.TODO()
+	coll := mongodbtest.NewClient().Database("testdb").CollectionWith("users", mongodb.WithSoftDelete(""))
+
+	if _, err := coll.InsertMany(ctx, []interface{}{
+		bson.M{"_id": "u1", "name": "Subrato"},
+		bson.M{"_id": "u2", "name": "Priya"},
+		bson.M{"_id": "u3", "name": "Shekhar"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	count := func(ctx context.Context) int64 {
+		t.Helper()
+		n, err := coll.CountDocuments(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	res, err := coll.DeleteOne(ctx, bson.M{"_id": "u1"})
+	if err != nil || res.DeletedCount != 1 {
+		t.Fatalf("DeleteOne = %+v, %v", res, err)
+	}
+	if res, _ := coll.DeleteOne(ctx, bson.M{"_id": "u1"}); res.DeletedCount != 0 {
+		t.Fatalf("second DeleteOne deleted %d", res.DeletedCount)
+	}
+
+	var u bson.M
+	if err := coll.FindOne(ctx, bson.M{"_id": "u1"}, &u); err != mongo.ErrNoDocuments {
+		t.Fatalf("FindOne of a deleted document = %v", err)
+	}
+	if err := coll.FindOne(mongodb.WithDeleted(ctx), bson.M{"_id": "u1"}, &u); err != nil || u["deletedAt"] == nil {
+		t.Fatalf("FindOne WithDeleted = %v, %v", u, err)
+	}
+	if n, all, only := count(ctx), count(mongodb.WithDeleted(ctx)), count(mongodb.OnlyDeleted(ctx)); n != 2 || all != 3 || only != 1 {
+		t.Fatalf("counts = %d, %d, %d", n, all, only)
+	}
+
+	var names []bson.M
+	if err := coll.Aggregate(ctx, mongo.Pipeline{{{Key: "$sort", Value: bson.M{"name": 1}}}}, &names); err != nil || len(names) != 2 {
+		t.Fatalf("Aggregate = %v, %v", names, err)
+	}
+
+	sd := coll.(mongodb.SoftDeleter)
+	if res, err := sd.Restore(ctx, bson.M{}); err != nil || res.ModifiedCount != 1 || count(ctx) != 3 {
+		t.Fatalf("Restore = %+v, %v", res, err)
+	}
+
+	if _, err := coll.DeleteMany(ctx, bson.M{"name": bson.M{"$ne": "Priya"}}); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := sd.Purge(ctx, bson.M{"_id": "u1"}); err != nil || res.DeletedCount != 1 {
+		t.Fatalf("Purge = %+v, %v", res, err)
+	}
+	if n, all := count(ctx), count(mongodb.WithDeleted(ctx)); n != 1 || all != 2 {
+		t.Fatalf("after Purge counts = %d, %d", n, all)
+	}
+}
+
+func TestSoftDeleteWithVersion(t *testing.T) {
+	ctx := context.TODO()
+	coll := mongodbtest.NewClient().Database("testdb").CollectionWith("accounts",
+		mongodb.WithStructTags(account{}), mongodb.WithSoftDelete(""))
+
+	if _, err := coll.InsertOne(ctx, account{Id: "a1", Balance: 10}); err != nil {
+		t.Fatal(err)
+	}
+
+	stale := bson.M{"_id": "a1", "version": 7}
+	if _, err := coll.UpdateOne(ctx, stale, bson.M{"$inc": bson.M{"balance": 1}}); !errors.Is(err, mongodb.ErrVersionConflict) {
+		t.Errorf("UpdateOne with a stale version = %v", err)
+	}
+	var a account
+	if err := coll.FindOneAndUpdate(ctx, stale, bson.M{"$inc": bson.M{"balance": 1}}, &a); !errors.Is(err, mongodb.ErrVersionConflict) {
+		t.Errorf("FindOneAndUpdate with a stale version = %v", err)
+	}
+	if _, err := coll.ReplaceOne(ctx, bson.M{"_id": "a1"}, account{Id: "a1", Version: 7}); !errors.Is(err, mongodb.ErrVersionConflict) {
+		t.Errorf("ReplaceOne with a stale version = %v", err)
+	}
+	if res, err := coll.UpdateOne(ctx, bson.M{"_id": "a1", "version": 1}, bson.M{"$inc": bson.M{"balance": 1}}); err != nil || res.ModifiedCount != 1 {
+		t.Errorf("UpdateOne with the current version = %+v, %v", res, err)
+	}
+}
+
+func TestSoftDeleteClone(t *testing.T) {
+	ctx := context.TODO()
+	coll := mongodbtest.NewClient().Database("testdb").CollectionWith("accounts",
+		mongodb.WithTimestamps("createdAt", "updatedAt"), mongodb.WithSoftDelete(""))
+	clone, err := coll.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := clone.InsertMany(ctx, []interface{}{bson.M{"_id": "a1"}, bson.M{"_id": "a2"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := clone.DeleteMany(ctx, bson.M{}); err != nil {
+		t.Fatal(err)
+	}
+	sd := clone.(mongodb.SoftDeleter)
+	if res, err := sd.Restore(ctx, bson.M{"_id": "a1"}); err != nil || res.MatchedCount != 1 {
+		t.Errorf("Restore on a clone = %+v, %v", res, err)
+	}
+	if res, err := sd.Purge(ctx, bson.M{"_id": "a2"}); err != nil || res.DeletedCount != 1 {
+		t.Errorf("Purge on a clone = %+v, %v", res, err)
+	}
+}
@@ -0,0 +1,338 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/subratohld/mongodb/internal/bsonutil"
+	"github.com/subratohld/mongodb/internal/clock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrVersionConflict is matched by every *VersionConflictError.
+var ErrVersionConflict = errors.New("mongodb: version conflict")
+
+// VersionConflictError reports a versioned write that matched no document,
+// because the document was changed or removed since Version was read.
+type VersionConflictError struct {
+	Collection string
+	Version    interface{}
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("mongodb: version conflict in %s: no document with version %v matched", e.Collection, e.Version)
+}
+
+func (e *VersionConflictError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
+
+// stampedCollection maintains the timestamp and version fields of
+// collectionConfig. BulkWrite is passed through unchanged.
+type stampedCollection struct {
+	Collection
+	cfg collectionConfig
+}
+
+func (sc *stampedCollection) conflict(version interface{}) error {
+	return &VersionConflictError{Collection: sc.Name(), Version: version}
+}
+
+func (sc *stampedCollection) Clone(opts ...*options.CollectionOptions) (Collection, error) {
+	c, err := sc.Collection.Clone(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &stampedCollection{Collection: c, cfg: sc.cfg}, nil
+}
+
+func (sc *stampedCollection) InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (interface{}, error) {
+	doc, err := sc.stampInsert(document, clock.From(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return sc.Collection.InsertOne(ctx, doc, opts...)
+}
+
+func (sc *stampedCollection) InsertMany(ctx context.Context, documents []interface{}, opts ...*options.InsertManyOptions) ([]interface{}, error) {
+	t := clock.From(ctx)
+	docs := make([]interface{}, len(documents))
+	for i, document := range documents {
+		doc, err := sc.stampInsert(document, t)
+		if err != nil {
+			return nil, err
+		}
+		docs[i] = doc
+	}
+	return sc.Collection.InsertMany(ctx, docs, opts...)
+}
+
+func (sc *stampedCollection) UpdateByID(ctx context.Context, id interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return sc.UpdateOne(ctx, bson.D{{Key: "_id", Value: id}}, update, opts...)
+}
+
+func (sc *stampedCollection) UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	u, err := sc.stampUpdate(update, clock.From(ctx))
+	if err != nil {
+		return nil, err
+	}
+	version, versioned, err := sc.expectedVersion(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := sc.Collection.UpdateOne(ctx, filter, u, opts...)
+	if versioned {
+		if err == nil && res.MatchedCount == 0 && res.UpsertedCount == 0 || mongo.IsDuplicateKeyError(err) {
+			return nil, sc.conflict(version)
+		}
+	}
+	return res, err
+}
+
+func (sc *stampedCollection) UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	u, err := sc.stampUpdate(update, clock.From(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return sc.Collection.UpdateMany(ctx, filter, u, opts...)
+}
+
+func (sc *stampedCollection) FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, target interface{}, opts ...*options.FindOneAndUpdateOptions) error {
+	u, err := sc.stampUpdate(update, clock.From(ctx))
+	if err != nil {
+		return err
+	}
+	version, versioned, err := sc.expectedVersion(filter)
+	if err != nil {
+		return err
+	}
+
+	err = sc.Collection.FindOneAndUpdate(ctx, filter, u, target, opts...)
+	if versioned && (errors.Is(err, mongo.ErrNoDocuments) || mongo.IsDuplicateKeyError(err)) {
+		return sc.conflict(version)
+	}
+	return err
+}
+
+func (sc *stampedCollection) ReplaceOne(ctx context.Context, filter interface{}, replacement interface{}, opts ...*options.ReplaceOptions) (*mongo.UpdateResult, error) {
+	filter, doc, version, versioned, err := sc.stampReplace(filter, replacement, clock.From(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := sc.Collection.ReplaceOne(ctx, filter, doc, opts...)
+	if versioned {
+		if err == nil && res.MatchedCount == 0 && res.UpsertedCount == 0 || mongo.IsDuplicateKeyError(err) {
+			return nil, sc.conflict(version)
+		}
+	}
+	return res, err
+}
+
+func (sc *stampedCollection) FindOneAndReplace(ctx context.Context, filter interface{}, replacement interface{}, target interface{}, opts ...*options.FindOneAndReplaceOptions) error {
+	filter, doc, version, versioned, err := sc.stampReplace(filter, replacement, clock.From(ctx))
+	if err != nil {
+		return err
+	}
+
+	err = sc.Collection.FindOneAndReplace(ctx, filter, doc, target, opts...)
+	if versioned && (errors.Is(err, mongo.ErrNoDocuments) || mongo.IsDuplicateKeyError(err)) {
+		return sc.conflict(version)
+	}
+	return err
+}
+
+func (sc *stampedCollection) stampInsert(document interface{}, t time.Time) (bson.D, error) {
+	doc, err := bsonutil.ToDoc(document)
+	if err != nil {
+		return nil, err
+	}
+
+	doc = setField(doc, sc.cfg.createdAt, t)
+	doc = setField(doc, sc.cfg.updatedAt, t)
+	if sc.cfg.version != "" {
+		if v, ok := bsonutil.Lookup(doc, sc.cfg.version); !ok || isZero(v) {
+			doc = setField(doc, sc.cfg.version, int64(1))
+		}
+	}
+	return doc, nil
+}
+
+// stampReplace stamps replacement and, for versioned collections, restricts
+// filter to the replacement's version, which it then increments. A
+// replacement without a version is treated as a new document: it gets
+// version 1 and the filter is left alone. The replacement keeps the
+// createdAt it carries and is given the current time if it has none.
+func (sc *stampedCollection) stampReplace(filter, replacement interface{}, t time.Time) (interface{}, bson.D, interface{}, bool, error) {
+	doc, err := bsonutil.ToDoc(replacement)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	if v, ok := bsonutil.Lookup(doc, sc.cfg.createdAt); sc.cfg.createdAt != "" && (!ok || isZero(v)) {
+		doc = setField(doc, sc.cfg.createdAt, t)
+	}
+	doc = setField(doc, sc.cfg.updatedAt, t)
+
+	if sc.cfg.version == "" {
+		return filter, doc, nil, false, nil
+	}
+	version, ok := bsonutil.Lookup(doc, sc.cfg.version)
+	if !ok || isZero(version) {
+		return filter, setField(doc, sc.cfg.version, int64(1)), nil, false, nil
+	}
+	next, err := increment(version)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	doc = setField(doc, sc.cfg.version, next)
+	filter = bson.D{{Key: "$and", Value: bson.A{orEmpty(filter), bson.D{{Key: sc.cfg.version, Value: version}}}}}
+	return filter, doc, version, true, nil
+}
+
+// stampUpdate adds the timestamp and version changes to an update document
+// or pipeline, leaving fields the update already sets alone.
+func (sc *stampedCollection) stampUpdate(update interface{}, t time.Time) (interface{}, error) {
+	if isPipeline(update) {
+		stages, err := bsonutil.ToDocs(update)
+		if err != nil {
+			return nil, err
+		}
+		var set bson.D
+		set = setField(set, sc.cfg.updatedAt, t)
+		if sc.cfg.createdAt != "" {
+			set = setField(set, sc.cfg.createdAt, bson.D{{Key: "$ifNull", Value: bson.A{"$" + sc.cfg.createdAt, t}}})
+		}
+		if sc.cfg.version != "" {
+			set = setField(set, sc.cfg.version, bson.D{{Key: "$add", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{"$" + sc.cfg.version, 0}}}, 1}}})
+		}
+		return append(stages, bson.D{{Key: "$set", Value: set}}), nil
+	}
+
+	u, err := bsonutil.ToDoc(update)
+	if err != nil {
+		return nil, err
+	}
+	var touched []string
+	for _, e := range u {
+		if fields, err := bsonutil.ToDoc(e.Value); err == nil {
+			for _, f := range fields {
+				touched = append(touched, f.Key)
+			}
+		}
+	}
+	untouched := func(name string) bool {
+		if name == "" {
+			return false
+		}
+		for _, f := range touched {
+			if f == name {
+				return false
+			}
+		}
+		return true
+	}
+
+	if untouched(sc.cfg.updatedAt) {
+		u = addOperator(u, "$set", sc.cfg.updatedAt, t)
+	}
+	if untouched(sc.cfg.createdAt) {
+		u = addOperator(u, "$setOnInsert", sc.cfg.createdAt, t)
+	}
+	if untouched(sc.cfg.version) {
+		u = addOperator(u, "$inc", sc.cfg.version, int64(1))
+	}
+	return u, nil
+}
+
+// expectedVersion returns the version named at the top level of filter or
+// of the clauses of a top-level $and, such as the one soft deletes add.
+func (sc *stampedCollection) expectedVersion(filter interface{}) (interface{}, bool, error) {
+	if sc.cfg.version == "" || filter == nil {
+		return nil, false, nil
+	}
+	f, err := bsonutil.ToDoc(filter)
+	if err != nil {
+		return nil, false, err
+	}
+	if v, ok := bsonutil.Lookup(f, sc.cfg.version); ok {
+		return v, true, nil
+	}
+	clauses, ok := bsonutil.Lookup(f, "$and")
+	if !ok {
+		return nil, false, nil
+	}
+	docs, err := bsonutil.ToDocs(clauses)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, clause := range docs {
+		if v, ok, err := sc.expectedVersion(clause); ok || err != nil {
+			return v, ok, err
+		}
+	}
+	return nil, false, nil
+}
+
+func isPipeline(v interface{}) bool {
+	switch v.(type) {
+	case bson.D, bson.Raw, []byte:
+		return false
+	}
+	k := reflect.ValueOf(v).Kind()
+	return k == reflect.Slice || k == reflect.Array
+}
+
+// setField sets key in doc, appending it if absent. An empty key leaves doc
+// unchanged.
+func setField(doc bson.D, key string, value interface{}) bson.D {
+	if key == "" {
+		return doc
+	}
+	for i, e := range doc {
+		if e.Key == key {
+			doc[i].Value = value
+			return doc
+		}
+	}
+	return append(doc, bson.E{Key: key, Value: value})
+}
+
+func addOperator(u bson.D, op, key string, value interface{}) bson.D {
+	for i, e := range u {
+		if e.Key == op {
+			fields, _ := bsonutil.ToDoc(e.Value)
+			u[i].Value = setField(fields, key, value)
+			return u
+		}
+	}
+	return append(u, bson.E{Key: op, Value: bson.D{{Key: key, Value: value}}})
+}
+
+// isZero reports whether v is unset, counting the date a zero time.Time is
+// stored as.
+func isZero(v interface{}) bool {
+	if dt, ok := v.(primitive.DateTime); ok {
+		return dt.Time().IsZero()
+	}
+	return v == nil || reflect.ValueOf(v).IsZero()
+}
+
+func increment(v interface{}) (interface{}, error) {
+	switch n := v.(type) {
+	case int32:
+		return n + 1, nil
+	case int64:
+		return n + 1, nil
+	case float64:
+		return n + 1, nil
+	}
+	return nil, fmt.Errorf("mongodb: version %v is not a number", v)
+}
@@ -0,0 +1,94 @@
+package mongodb_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/subratohld/mongodb"
+	"github.com/subratohld/mongodb/mongodbtest"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type account struct {
+	Id        string    `bson:"_id"`
+	Balance   int       `bson:"balance"`
+	CreatedAt time.Time `bson:"createdAt" mongodb:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" mongodb:"updatedAt"`
+	Version   int64     `bson:"version" mongodb:"version"`
+}
+
+func TestTimestampsAndVersion(t *testing.T) {
+	ctx := context.TODO()
+	coll := mongodbtest.NewClient().Database("testdb").CollectionWith("accounts", mongodb.WithStructTags(account{}))
+
+	get := func() account {
+		t.Helper()
+		var a account
+		if err := coll.FindOne(ctx, bson.M{"_id": "a1"}, &a); err != nil {
+			t.Fatal(err)
+		}
+		return a
+	}
+
+	if _, err := coll.InsertOne(ctx, account{Id: "a1", Balance: 10}); err != nil {
+		t.Fatal(err)
+	}
+	created := get()
+	if created.CreatedAt.IsZero() || !created.UpdatedAt.Equal(created.CreatedAt) || created.Version != 1 {
+		t.Fatalf("after InsertOne = %+v", created)
+	}
+
+	time.Sleep(2 * time.Millisecond)
+	if _, err := coll.UpdateByID(ctx, "a1", bson.M{"$inc": bson.M{"balance": 5}}); err != nil {
+		t.Fatal(err)
+	}
+	updated := get()
+	if updated.Balance != 15 || updated.Version != 2 || !updated.CreatedAt.Equal(created.CreatedAt) || !updated.UpdatedAt.After(created.UpdatedAt) {
+		t.Fatalf("after UpdateByID = %+v", updated)
+	}
+
+	stale := created
+	stale.Balance = 100
+	_, err := coll.ReplaceOne(ctx, bson.M{"_id": "a1"}, stale)
+	var conflict *mongodb.VersionConflictError
+	if !errors.Is(err, mongodb.ErrVersionConflict) || !errors.As(err, &conflict) || conflict.Version != int64(1) {
+		t.Fatalf("ReplaceOne with a stale version = %v", err)
+	}
+
+	updated.Balance = 20
+	if _, err := coll.ReplaceOne(ctx, bson.M{"_id": "a1"}, updated); err != nil {
+		t.Fatalf("ReplaceOne: %v", err)
+	}
+	if a := get(); a.Balance != 20 || a.Version != 3 || !a.CreatedAt.Equal(created.CreatedAt) {
+		t.Fatalf("after ReplaceOne = %+v", a)
+	}
+
+	_, err = coll.UpdateOne(ctx, bson.M{"_id": "a1", "version": 2}, bson.M{"$set": bson.M{"balance": 0}})
+	if !errors.Is(err, mongodb.ErrVersionConflict) {
+		t.Errorf("UpdateOne with a stale version = %v", err)
+	}
+	if err := coll.FindOneAndUpdate(ctx, bson.M{"_id": "a1", "version": 3}, bson.M{"$set": bson.M{"balance": 0}}, nil); err != nil {
+		t.Errorf("FindOneAndUpdate with the current version = %v", err)
+	}
+}
+
+func TestReplaceUpsertsUnversionedDocument(t *testing.T) {
+	ctx := context.TODO()
+	coll := mongodbtest.NewClient().Database("testdb").CollectionWith("accounts", mongodb.WithStructTags(account{}))
+
+	res, err := coll.ReplaceOne(ctx, bson.M{"_id": "a2"}, account{Id: "a2", Balance: 5}, options.Replace().SetUpsert(true))
+	if err != nil || res.UpsertedCount != 1 {
+		t.Fatalf("ReplaceOne upsert = %+v, %v", res, err)
+	}
+
+	var a account
+	if err := coll.FindOne(ctx, bson.M{"_id": "a2"}, &a); err != nil {
+		t.Fatal(err)
+	}
+	if a.Version != 1 || a.CreatedAt.IsZero() {
+		t.Errorf("upserted account = %+v", a)
+	}
+}
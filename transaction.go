@@ -0,0 +1,156 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+const (
+	labelTransientTransaction = "TransientTransactionError"
+	labelUnknownCommitResult  = "UnknownTransactionCommitResult"
+
+	defaultTxTimeout = 120 * time.Second
+)
+
+// TxContext is handed to the function run by Client.WithTransaction. It is
+// a context carrying the transaction's session, and the Database and
+// Collection handles it returns run every operation transactions allow
+// inside the transaction whatever context they are called with. Drops,
+// listings, estimated counts and change streams run outside it.
+type TxContext interface {
+	context.Context
+	Session() mongo.Session
+	Database(name string, opts ...*options.DatabaseOptions) Database
+}
+
+// TxOption configures a transaction started by Client.WithTransaction.
+type TxOption func(*txConfig)
+
+type txConfig struct {
+	txOpts  *options.TransactionOptions
+	timeout time.Duration
+}
+
+func newTxConfig(opts ...TxOption) *txConfig {
+	cfg := &txConfig{
+		txOpts:  options.Transaction(),
+		timeout: defaultTxTimeout,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+func WithTxReadConcern(rc *readconcern.ReadConcern) TxOption {
+	return func(cfg *txConfig) {
+		cfg.txOpts.SetReadConcern(rc)
+	}
+}
+
+func WithTxWriteConcern(wc *writeconcern.WriteConcern) TxOption {
+	return func(cfg *txConfig) {
+		cfg.txOpts.SetWriteConcern(wc)
+	}
+}
+
+func WithTxReadPreference(rp *readpref.ReadPref) TxOption {
+	return func(cfg *txConfig) {
+		cfg.txOpts.SetReadPreference(rp)
+	}
+}
+
+// WithTxTimeout bounds how long a transaction keeps being retried. The
+// default is 120 seconds, as in the driver.
+func WithTxTimeout(d time.Duration) TxOption {
+	return func(cfg *txConfig) {
+		cfg.timeout = d
+	}
+}
+
+type txContext struct {
+	mongo.SessionContext
+	client *client
+}
+
+func (tx *txContext) Session() mongo.Session {
+	return tx.SessionContext
+}
+
+func (tx *txContext) Database(name string, opts ...*options.DatabaseOptions) Database {
+	return newDB(tx.client, tx.SessionContext, name, opts...)
+}
+
+// WithTransaction runs fn in a transaction and commits it. The whole
+// transaction is retried when an error carries the TransientTransactionError
+// label, and the commit alone is retried on UnknownTransactionCommitResult,
+// until the timeout set with WithTxTimeout has passed.
+func (m *client) WithTransaction(ctx context.Context, fn func(tx TxContext) error, opts ...TxOption) error {
+	cfg := newTxConfig(opts...)
+
+	sess, err := m.Client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer sess.EndSession(ctx)
+
+	return runTransaction(ctx, sess, cfg, func(sessCtx mongo.SessionContext) TxContext {
+		return &txContext{SessionContext: sessCtx, client: m}
+	}, fn)
+}
+
+func runTransaction(ctx context.Context, sess mongo.Session, cfg *txConfig, newTx func(mongo.SessionContext) TxContext, fn func(tx TxContext) error) error {
+	deadline := time.Now().Add(cfg.timeout)
+	for {
+		if err := sess.StartTransaction(cfg.txOpts); err != nil {
+			return err
+		}
+
+		if err := fn(newTx(mongo.NewSessionContext(ctx, sess))); err != nil {
+			_ = sess.AbortTransaction(context.Background())
+			if canRetry(ctx, deadline) && hasErrorLabel(err, labelTransientTransaction) {
+				continue
+			}
+			return err
+		}
+
+		err := commit(ctx, sess, deadline)
+		if err != nil && canRetry(ctx, deadline) && hasErrorLabel(err, labelTransientTransaction) {
+			continue
+		}
+		return err
+	}
+}
+
+func commit(ctx context.Context, sess mongo.Session, deadline time.Time) error {
+	for {
+		err := sess.CommitTransaction(ctx)
+		if err == nil || !canRetry(ctx, deadline) {
+			return err
+		}
+
+		var cerr mongo.CommandError
+		if hasErrorLabel(err, labelUnknownCommitResult) && !(errors.As(err, &cerr) && cerr.IsMaxTimeMSExpiredError()) {
+			continue
+		}
+		return err
+	}
+}
+
+func canRetry(ctx context.Context, deadline time.Time) bool {
+	return ctx.Err() == nil && time.Now().Before(deadline)
+}
+
+func hasErrorLabel(err error, label string) bool {
+	var le interface{ HasErrorLabel(string) bool }
+	return errors.As(err, &le) && le.HasErrorLabel(label)
+}
@@ -0,0 +1,154 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+type fakeSession struct {
+	mongo.Session
+	commitErrs []error
+	txOpts     []*options.TransactionOptions
+	starts     int
+	aborts     int
+	commits    int
+}
+
+func (s *fakeSession) StartTransaction(opts ...*options.TransactionOptions) error {
+	s.starts++
+	s.txOpts = opts
+	return nil
+}
+
+func (s *fakeSession) AbortTransaction(ctx context.Context) error {
+	s.aborts++
+	return nil
+}
+
+func (s *fakeSession) CommitTransaction(ctx context.Context) error {
+	s.commits++
+	if len(s.commitErrs) == 0 {
+		return nil
+	}
+	err := s.commitErrs[0]
+	s.commitErrs = s.commitErrs[1:]
+	return err
+}
+
+type fakeTx struct {
+	mongo.SessionContext
+}
+
+func (tx fakeTx) Session() mongo.Session {
+	return tx.SessionContext
+}
+
+func (tx fakeTx) Database(name string, opts ...*options.DatabaseOptions) Database {
+	return nil
+}
+
+func newFakeTx(sessCtx mongo.SessionContext) TxContext {
+	return fakeTx{sessCtx}
+}
+
+func labeled(label string) error {
+	return mongo.CommandError{Code: 112, Message: "write conflict", Labels: []string{label}}
+}
+
+func TestRunTransactionRetries(t *testing.T) {
+	errPlain := errors.New("boom")
+
+	tests := []struct {
+		name       string
+		fnErrs     []error
+		commitErrs []error
+		wantErr    error
+		calls      int
+		commits    int
+		aborts     int
+	}{
+		{name: "commit", calls: 1, commits: 1},
+		{name: "transient error in fn", fnErrs: []error{labeled(labelTransientTransaction)}, calls: 2, commits: 1, aborts: 1},
+		{name: "plain error in fn", fnErrs: []error{errPlain}, wantErr: errPlain, calls: 1, aborts: 1},
+		{name: "unknown commit result", commitErrs: []error{labeled(labelUnknownCommitResult)}, calls: 1, commits: 2},
+		{name: "transient commit error", commitErrs: []error{labeled(labelTransientTransaction)}, calls: 2, commits: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sess := &fakeSession{commitErrs: tt.commitErrs}
+			fnErrs := tt.fnErrs
+			var calls int
+
+			err := runTransaction(context.TODO(), sess, newTxConfig(), newFakeTx, func(tx TxContext) error {
+				calls++
+				if mongo.SessionFromContext(tx) != sess {
+					t.Error("tx does not carry the session")
+				}
+				if len(fnErrs) == 0 {
+					return nil
+				}
+				err := fnErrs[0]
+				fnErrs = fnErrs[1:]
+				return err
+			})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.calls || sess.commits != tt.commits || sess.aborts != tt.aborts {
+				t.Errorf("calls = %d, commits = %d, aborts = %d, want %d, %d, %d",
+					calls, sess.commits, sess.aborts, tt.calls, tt.commits, tt.aborts)
+			}
+		})
+	}
+}
+
+func TestRunTransactionOptions(t *testing.T) {
+	sess := &fakeSession{}
+	wc := writeconcern.New(writeconcern.WMajority())
+
+	err := runTransaction(context.TODO(), sess, newTxConfig(WithTxWriteConcern(wc)), newFakeTx, func(tx TxContext) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("runTransaction: %v", err)
+	}
+	if len(sess.txOpts) != 1 || sess.txOpts[0].WriteConcern != wc {
+		t.Errorf("transaction options = %+v, want the write concern override", sess.txOpts)
+	}
+}
+
+func TestRunTransactionStopsAtTimeout(t *testing.T) {
+	sess := &fakeSession{}
+	var calls int
+
+	err := runTransaction(context.TODO(), sess, newTxConfig(WithTxTimeout(0)), newFakeTx, func(tx TxContext) error {
+		calls++
+		return labeled(labelTransientTransaction)
+	})
+	if !hasErrorLabel(err, labelTransientTransaction) || calls != 1 {
+		t.Errorf("err = %v after %d calls, want the transient error after 1 call", err, calls)
+	}
+}
+
+func TestDatabaseBind(t *testing.T) {
+	sess := &fakeSession{}
+	db := &database{sess: sess}
+
+	if got := mongo.SessionFromContext(db.bind(context.TODO())); got != sess {
+		t.Errorf("bound session = %v, want the transaction session", got)
+	}
+	other := &fakeSession{}
+	if got := mongo.SessionFromContext(db.bind(mongo.NewSessionContext(context.TODO(), other))); got != other {
+		t.Error("bind replaced the session already in ctx")
+	}
+	if got := mongo.SessionFromContext((&database{}).bind(context.TODO())); got != nil {
+		t.Errorf("unbound handle attached session %v", got)
+	}
+}
@@ -0,0 +1,144 @@
+package mongodb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TypedCollection is a Collection whose documents decode into T, so that
+// results come back as values of T instead of being written through an
+// interface{} target.
+type TypedCollection[T any] interface {
+	Collection() Collection
+	InsertOne(ctx context.Context, document T, opts ...*options.InsertOneOptions) (interface{}, error)
+	InsertMany(ctx context.Context, documents []T, opts ...*options.InsertManyOptions) ([]interface{}, error)
+	UpdateByID(ctx context.Context, id interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error)
+	UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error)
+	UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error)
+	ReplaceOne(ctx context.Context, filter interface{}, replacement T, opts ...*options.ReplaceOptions) (*mongo.UpdateResult, error)
+	DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error)
+	DeleteMany(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error)
+	FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) (*T, error)
+	Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) ([]T, error)
+	FindEach(ctx context.Context, filter interface{}, fn func(T) error, opts ...*options.FindOptions) error
+	FindOneAndDelete(ctx context.Context, filter interface{}, opts ...*options.FindOneAndDeleteOptions) (*T, error)
+	FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, opts ...*options.FindOneAndUpdateOptions) (*T, error)
+	FindOneAndReplace(ctx context.Context, filter interface{}, replacement T, opts ...*options.FindOneAndReplaceOptions) (*T, error)
+	Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) ([]T, error)
+	CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error)
+}
+
+// NewTypedCollection returns a view of coll decoding documents into T.
+func NewTypedCollection[T any](coll Collection) TypedCollection[T] {
+	return &typedCollection[T]{
+		coll: coll,
+	}
+}
+
+type typedCollection[T any] struct {
+	coll Collection
+}
+
+func (tc *typedCollection[T]) Collection() Collection {
+	return tc.coll
+}
+
+func (tc *typedCollection[T]) InsertOne(ctx context.Context, document T, opts ...*options.InsertOneOptions) (interface{}, error) {
+	return tc.coll.InsertOne(ctx, document, opts...)
+}
+
+func (tc *typedCollection[T]) InsertMany(ctx context.Context, documents []T, opts ...*options.InsertManyOptions) ([]interface{}, error) {
+	docs := make([]interface{}, len(documents))
+	for i, doc := range documents {
+		docs[i] = doc
+	}
+	return tc.coll.InsertMany(ctx, docs, opts...)
+}
+
+func (tc *typedCollection[T]) UpdateByID(ctx context.Context, id interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return tc.coll.UpdateByID(ctx, id, update, opts...)
+}
+
+func (tc *typedCollection[T]) UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return tc.coll.UpdateOne(ctx, filter, update, opts...)
+}
+
+func (tc *typedCollection[T]) UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return tc.coll.UpdateMany(ctx, filter, update, opts...)
+}
+
+func (tc *typedCollection[T]) ReplaceOne(ctx context.Context, filter interface{}, replacement T, opts ...*options.ReplaceOptions) (*mongo.UpdateResult, error) {
+	return tc.coll.ReplaceOne(ctx, filter, replacement, opts...)
+}
+
+func (tc *typedCollection[T]) DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	return tc.coll.DeleteOne(ctx, filter, opts...)
+}
+
+func (tc *typedCollection[T]) DeleteMany(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	return tc.coll.DeleteMany(ctx, filter, opts...)
+}
+
+func (tc *typedCollection[T]) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) (*T, error) {
+	var result T
+	if err := tc.coll.FindOne(ctx, filter, &result, opts...); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (tc *typedCollection[T]) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) ([]T, error) {
+	var results []T
+	if err := tc.coll.Find(ctx, filter, &results, opts...); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (tc *typedCollection[T]) FindEach(ctx context.Context, filter interface{}, fn func(T) error, opts ...*options.FindOptions) error {
+	return tc.coll.FindEach(ctx, filter, func(decode func(v interface{}) error) error {
+		var doc T
+		if err := decode(&doc); err != nil {
+			return err
+		}
+		return fn(doc)
+	}, opts...)
+}
+
+func (tc *typedCollection[T]) FindOneAndDelete(ctx context.Context, filter interface{}, opts ...*options.FindOneAndDeleteOptions) (*T, error) {
+	var result T
+	if err := tc.coll.FindOneAndDelete(ctx, filter, &result, opts...); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (tc *typedCollection[T]) FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, opts ...*options.FindOneAndUpdateOptions) (*T, error) {
+	var result T
+	if err := tc.coll.FindOneAndUpdate(ctx, filter, update, &result, opts...); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (tc *typedCollection[T]) FindOneAndReplace(ctx context.Context, filter interface{}, replacement T, opts ...*options.FindOneAndReplaceOptions) (*T, error) {
+	var result T
+	if err := tc.coll.FindOneAndReplace(ctx, filter, replacement, &result, opts...); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (tc *typedCollection[T]) Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) ([]T, error) {
+	var results []T
+	if err := tc.coll.Aggregate(ctx, pipeline, &results, opts...); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (tc *typedCollection[T]) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	return tc.coll.CountDocuments(ctx, filter, opts...)
+}
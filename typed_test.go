@@ -0,0 +1,45 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/subratohld/mongodb"
+	"github.com/subratohld/mongodb/mongodbtest"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type user struct {
+	Id   string `bson:"_id,omitempty"`
+	Name string `bson:"name"`
+	Age  int    `bson:"age"`
+}
+
+func TestTypedCollection(t *testing.T) {
+	ctx := context.TODO()
+	users := mongodb.NewTypedCollection[user](mongodbtest.NewClient().Database("testdb").Collection("users"))
+
+	ids, err := users.InsertMany(ctx, []user{{Name: "Priya", Age: 22}, {Name: "Shekhar", Age: 25}})
+	if err != nil || len(ids) != 2 {
+		t.Fatalf("InsertMany = %v, %v", ids, err)
+	}
+
+	found, err := users.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"age": -1}))
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if len(found) != 2 || found[0].Name != "Shekhar" || found[0].Id == "" {
+		t.Errorf("Find = %+v", found)
+	}
+
+	one, err := users.FindOne(ctx, bson.M{"name": "Priya"})
+	if err != nil || one.Age != 22 {
+		t.Errorf("FindOne = %+v, %v", one, err)
+	}
+
+	if _, err := users.FindOne(ctx, bson.M{"name": "nobody"}); err != mongo.ErrNoDocuments {
+		t.Errorf("FindOne err = %v, want mongo.ErrNoDocuments", err)
+	}
+}
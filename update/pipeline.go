@@ -0,0 +1,38 @@
+package update
+
+import "go.mongodb.org/mongo-driver/bson"
+
+// Pipeline is an update made of aggregation stages, which can compute new
+// values from the document's current fields. It can be passed wherever an
+// update is accepted.
+//
+//	u := update.NewPipeline().
+//		Set(bson.D{{Key: "total", Value: bson.D{{Key: "$add", Value: bson.A{"$price", "$tax"}}}}}).
+//		Unset("tax")
+type Pipeline []bson.D
+
+func NewPipeline() Pipeline {
+	return Pipeline{}
+}
+
+func (p Pipeline) stage(name string, value interface{}) Pipeline {
+	return append(p[:len(p):len(p)], bson.D{{Key: name, Value: value}})
+}
+
+// Set adds or replaces fields with the values of aggregation expressions.
+func (p Pipeline) Set(fields bson.D) Pipeline {
+	return p.stage("$set", fields)
+}
+
+func (p Pipeline) Unset(fields ...string) Pipeline {
+	return p.stage("$unset", fields)
+}
+
+func (p Pipeline) Project(spec bson.D) Pipeline {
+	return p.stage("$project", spec)
+}
+
+// ReplaceWith replaces the document with the result of expr.
+func (p Pipeline) ReplaceWith(expr interface{}) Pipeline {
+	return p.stage("$replaceWith", expr)
+}
@@ -0,0 +1,327 @@
+// Package update builds update documents for the mongodb package.
+//
+//	u := update.Set("status", "shipped").
+//		Inc("version", 1).
+//		CurrentDate("updatedAt")
+//	_, err := coll.UpdateByID(ctx, id, u)
+//
+// An Update marshals to BSON, so it can be passed wherever an update is
+// accepted. Paths are validated when the update is built or marshalled:
+// setting a path twice with different operators, or a path together with
+// one of its parents, is rejected before anything is sent to the server.
+package update
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Update is an update document made of update operators. The zero Update is
+// empty and not valid on its own.
+type Update struct {
+	ops          []op
+	arrayFilters []interface{}
+}
+
+type op struct {
+	operator string
+	path     string
+	value    interface{}
+}
+
+func (u Update) with(operator, path string, value interface{}) Update {
+	u.ops = append(u.ops[:len(u.ops):len(u.ops)], op{operator: operator, path: path, value: value})
+	return u
+}
+
+func Set(path string, value interface{}) Update {
+	return Update{}.Set(path, value)
+}
+
+func (u Update) Set(path string, value interface{}) Update {
+	return u.with("$set", path, value)
+}
+
+// SetOnInsert sets path only when an upsert inserts a document.
+func SetOnInsert(path string, value interface{}) Update {
+	return Update{}.SetOnInsert(path, value)
+}
+
+func (u Update) SetOnInsert(path string, value interface{}) Update {
+	return u.with("$setOnInsert", path, value)
+}
+
+func Unset(paths ...string) Update {
+	return Update{}.Unset(paths...)
+}
+
+func (u Update) Unset(paths ...string) Update {
+	for _, path := range paths {
+		u = u.with("$unset", path, "")
+	}
+	return u
+}
+
+func Inc(path string, n interface{}) Update {
+	return Update{}.Inc(path, n)
+}
+
+func (u Update) Inc(path string, n interface{}) Update {
+	return u.with("$inc", path, n)
+}
+
+func Mul(path string, n interface{}) Update {
+	return Update{}.Mul(path, n)
+}
+
+func (u Update) Mul(path string, n interface{}) Update {
+	return u.with("$mul", path, n)
+}
+
+// Min sets path to value if value is less than the current value.
+func Min(path string, value interface{}) Update {
+	return Update{}.Min(path, value)
+}
+
+func (u Update) Min(path string, value interface{}) Update {
+	return u.with("$min", path, value)
+}
+
+// Max sets path to value if value is greater than the current value.
+func Max(path string, value interface{}) Update {
+	return Update{}.Max(path, value)
+}
+
+func (u Update) Max(path string, value interface{}) Update {
+	return u.with("$max", path, value)
+}
+
+func Rename(from, to string) Update {
+	return Update{}.Rename(from, to)
+}
+
+func (u Update) Rename(from, to string) Update {
+	return u.with("$rename", from, to)
+}
+
+// CurrentDate sets path to the server's current date.
+func CurrentDate(path string) Update {
+	return Update{}.CurrentDate(path)
+}
+
+func (u Update) CurrentDate(path string) Update {
+	return u.with("$currentDate", path, true)
+}
+
+// CurrentTimestamp sets path to the server's current timestamp.
+func CurrentTimestamp(path string) Update {
+	return Update{}.CurrentTimestamp(path)
+}
+
+func (u Update) CurrentTimestamp(path string) Update {
+	return u.with("$currentDate", path, bson.D{{Key: "$type", Value: "timestamp"}})
+}
+
+// PushOption modifies a Push.
+type PushOption func(*bson.D)
+
+// Slice limits the array to its first n elements, or its last -n elements if
+// n is negative, after the push.
+func Slice(n int) PushOption {
+	return func(d *bson.D) {
+		*d = append(*d, bson.E{Key: "$slice", Value: n})
+	}
+}
+
+// Sort orders the array after the push. spec is 1 or -1 for arrays of
+// scalars, or a sort document for arrays of documents.
+func Sort(spec interface{}) PushOption {
+	return func(d *bson.D) {
+		*d = append(*d, bson.E{Key: "$sort", Value: spec})
+	}
+}
+
+// Position inserts the values at index n instead of appending them.
+func Position(n int) PushOption {
+	return func(d *bson.D) {
+		*d = append(*d, bson.E{Key: "$position", Value: n})
+	}
+}
+
+// Push appends values to the array at path.
+func Push(path string, values []interface{}, opts ...PushOption) Update {
+	return Update{}.Push(path, values, opts...)
+}
+
+func (u Update) Push(path string, values []interface{}, opts ...PushOption) Update {
+	if len(values) == 1 && len(opts) == 0 {
+		return u.with("$push", path, values[0])
+	}
+
+	d := bson.D{{Key: "$each", Value: bson.A(values)}}
+	for _, opt := range opts {
+		opt(&d)
+	}
+	return u.with("$push", path, d)
+}
+
+// AddToSet adds the values that are not already present to the array at
+// path.
+func AddToSet(path string, values ...interface{}) Update {
+	return Update{}.AddToSet(path, values...)
+}
+
+func (u Update) AddToSet(path string, values ...interface{}) Update {
+	if len(values) == 1 {
+		return u.with("$addToSet", path, values[0])
+	}
+	return u.with("$addToSet", path, bson.D{{Key: "$each", Value: bson.A(values)}})
+}
+
+// Pull removes the array elements at path that equal condition, or match it
+// when condition is a query such as a filter.Filter.
+func Pull(path string, condition interface{}) Update {
+	return Update{}.Pull(path, condition)
+}
+
+func (u Update) Pull(path string, condition interface{}) Update {
+	return u.with("$pull", path, condition)
+}
+
+func PullAll(path string, values ...interface{}) Update {
+	return Update{}.PullAll(path, values...)
+}
+
+func (u Update) PullAll(path string, values ...interface{}) Update {
+	return u.with("$pullAll", path, bson.A(values))
+}
+
+// PopFirst removes the first element of the array at path.
+func PopFirst(path string) Update {
+	return Update{}.PopFirst(path)
+}
+
+func (u Update) PopFirst(path string) Update {
+	return u.with("$pop", path, -1)
+}
+
+// PopLast removes the last element of the array at path.
+func PopLast(path string) Update {
+	return Update{}.PopLast(path)
+}
+
+func (u Update) PopLast(path string) Update {
+	return u.with("$pop", path, 1)
+}
+
+// WithArrayFilters adds the filters that select the elements for $[<id>]
+// positional operators in paths. They are not part of the update document:
+// pass ArrayFilters to the operation's options.
+func (u Update) WithArrayFilters(filters ...interface{}) Update {
+	u.arrayFilters = append(u.arrayFilters[:len(u.arrayFilters):len(u.arrayFilters)], filters...)
+	return u
+}
+
+// ArrayFilters returns the filters added by WithArrayFilters, e.g. for
+// options.Update().SetArrayFilters(u.ArrayFilters()).
+func (u Update) ArrayFilters() options.ArrayFilters {
+	return options.ArrayFilters{Filters: u.arrayFilters}
+}
+
+// Build validates the update and returns the update document. A path given
+// twice to the same operator keeps the last value.
+func (u Update) Build() (bson.D, error) {
+	if len(u.ops) == 0 {
+		return nil, errors.New("update: empty update")
+	}
+
+	ops := dedupe(u.ops)
+	if err := checkPaths(ops); err != nil {
+		return nil, err
+	}
+
+	var d bson.D
+	index := make(map[string]int)
+	for _, o := range ops {
+		i, ok := index[o.operator]
+		if !ok {
+			i = len(d)
+			index[o.operator] = i
+			d = append(d, bson.E{Key: o.operator, Value: bson.D{}})
+		}
+		d[i].Value = append(d[i].Value.(bson.D), bson.E{Key: o.path, Value: o.value})
+	}
+	return d, nil
+}
+
+// Validate reports whether the update can be built.
+func (u Update) Validate() error {
+	_, err := u.Build()
+	return err
+}
+
+func (u Update) MarshalBSON() ([]byte, error) {
+	d, err := u.Build()
+	if err != nil {
+		return nil, err
+	}
+	return bson.Marshal(d)
+}
+
+// dedupe drops operations overridden by a later one with the same operator
+// and path.
+func dedupe(ops []op) []op {
+	out := make([]op, 0, len(ops))
+	index := make(map[[2]string]int)
+	for _, o := range ops {
+		key := [2]string{o.operator, o.path}
+		if i, ok := index[key]; ok {
+			out[i] = o
+			continue
+		}
+		index[key] = len(out)
+		out = append(out, o)
+	}
+	return out
+}
+
+func checkPaths(ops []op) error {
+	type target struct {
+		operator string
+		path     string
+	}
+
+	var targets []target
+	for _, o := range ops {
+		if o.path == "" {
+			return fmt.Errorf("update: empty path for %s", o.operator)
+		}
+		targets = append(targets, target{o.operator, o.path})
+		if o.operator == "$rename" {
+			to, _ := o.value.(string)
+			if to == "" {
+				return fmt.Errorf("update: empty target for $rename of %q", o.path)
+			}
+			targets = append(targets, target{o.operator, to})
+		}
+	}
+
+	for i, a := range targets {
+		for _, b := range targets[i+1:] {
+			if overlaps(a.path, b.path) {
+				return fmt.Errorf("update: %s of %q conflicts with %s of %q", a.operator, a.path, b.operator, b.path)
+			}
+		}
+	}
+	return nil
+}
+
+// overlaps reports whether a and b are the same path or one contains the
+// other.
+func overlaps(a, b string) bool {
+	return a == b || strings.HasPrefix(a, b+".") || strings.HasPrefix(b, a+".")
+}
@@ -0,0 +1,100 @@
+package update_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/subratohld/mongodb/mongodbtest"
+	"github.com/subratohld/mongodb/update"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestBuild(t *testing.T) {
+	u := update.Set("name", "Priya").
+		Inc("age", 1).
+		Set("name", "Shekhar").
+		Unset("nickname").
+		Push("scores", []interface{}{90, 75}, update.Sort(-1), update.Slice(3)).
+		CurrentDate("updatedAt")
+
+	got, err := u.Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	want := bson.D{
+		{Key: "$set", Value: bson.D{{Key: "name", Value: "Shekhar"}}},
+		{Key: "$inc", Value: bson.D{{Key: "age", Value: 1}}},
+		{Key: "$unset", Value: bson.D{{Key: "nickname", Value: ""}}},
+		{Key: "$push", Value: bson.D{{Key: "scores", Value: bson.D{
+			{Key: "$each", Value: bson.A{90, 75}},
+			{Key: "$sort", Value: -1},
+			{Key: "$slice", Value: 3},
+		}}}},
+		{Key: "$currentDate", Value: bson.D{{Key: "updatedAt", Value: true}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Build = %v, want %v", got, want)
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	tests := []struct {
+		name string
+		u    update.Update
+		want string
+	}{
+		{"empty", update.Update{}, "empty update"},
+		{"same path", update.Set("a", 1).Inc("a", 1), `$set of "a" conflicts with $inc of "a"`},
+		{"parent path", update.Set("a.b", 1).Unset("a"), `$set of "a.b" conflicts with $unset of "a"`},
+		{"rename target", update.Rename("a", "b").Set("b.c", 1), `$rename of "b" conflicts with $set of "b.c"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.u.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate = %v, want %q", err, tt.want)
+			}
+			if _, err := bson.Marshal(tt.u); err == nil {
+				t.Error("Marshal succeeded for an invalid update")
+			}
+		})
+	}
+
+	if err := update.Set("a.b", 1).Set("a.c", 1).Set("ab", 1).Validate(); err != nil {
+		t.Errorf("Validate of sibling paths = %v", err)
+	}
+}
+
+func TestArrayFilters(t *testing.T) {
+	u := update.Set("grades.$[g].passed", true).WithArrayFilters(bson.M{"g.score": bson.M{"$gte": 50}})
+	opts := options.Update().SetArrayFilters(u.ArrayFilters())
+	if len(opts.ArrayFilters.Filters) != 1 {
+		t.Errorf("ArrayFilters = %v", opts.ArrayFilters.Filters)
+	}
+}
+
+func TestUpdateOne(t *testing.T) {
+	ctx := context.TODO()
+	coll := mongodbtest.NewClient().Database("testdb").Collection("users")
+	if _, err := coll.InsertOne(ctx, bson.M{"_id": 1, "name": "Priya", "age": 22, "nickname": "P"}); err != nil {
+		t.Fatal(err)
+	}
+
+	u := update.Set("name", "Priya S").Inc("age", 1).Unset("nickname").Push("tags", []interface{}{"dev", "ops"})
+	if _, err := coll.UpdateByID(ctx, 1, u); err != nil {
+		t.Fatalf("UpdateByID: %v", err)
+	}
+
+	var got bson.M
+	if err := coll.FindOne(ctx, bson.M{"_id": 1}, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := bson.M{"_id": int32(1), "name": "Priya S", "age": int32(23), "tags": bson.A{"dev", "ops"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("document = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,158 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// Steps of connection setup reported by ConnectError.
+const (
+	StepConnect        = "connect"
+	StepPing           = "ping"
+	StepVerifyDatabase = "verify database"
+)
+
+// ConnectError reports which step of connection setup failed.
+type ConnectError struct {
+	Step string
+	// Database is set when Step is StepVerifyDatabase.
+	Database string
+	// Attempts is the number of pings made when Step is StepPing.
+	Attempts int
+	Err      error
+}
+
+func (e *ConnectError) Error() string {
+	switch e.Step {
+	case StepPing:
+		return fmt.Sprintf("mongodb: %s failed after %d attempt(s): %v", e.Step, e.Attempts, e.Err)
+	case StepVerifyDatabase:
+		return fmt.Sprintf("mongodb: %s %q failed: %v", e.Step, e.Database, e.Err)
+	}
+	return fmt.Sprintf("mongodb: %s failed: %v", e.Step, e.Err)
+}
+
+func (e *ConnectError) Unwrap() error {
+	return e.Err
+}
+
+// verifyConfig controls how NewClient checks a fresh connection. The zero
+// value pings the primary once.
+type verifyConfig struct {
+	skip      bool
+	readPref  *readpref.ReadPref
+	backoff   time.Duration
+	deadline  time.Duration
+	databases []string
+}
+
+// WithoutVerification connects lazily: NewClient returns without contacting
+// the server.
+func WithoutVerification() Option {
+	return func(cfg *clientConfig) {
+		cfg.verify.skip = true
+	}
+}
+
+// WithPingReadPreference pings a server matching rp instead of the primary,
+// e.g. readpref.SecondaryPreferred() to tolerate a primary election.
+func WithPingReadPreference(rp *readpref.ReadPref) Option {
+	return func(cfg *clientConfig) {
+		cfg.verify.skip = false
+		cfg.verify.readPref = rp
+	}
+}
+
+// WithPingRetry retries a failed ping, doubling the wait from backoff between
+// attempts, until deadline has passed since the first attempt.
+func WithPingRetry(backoff, deadline time.Duration) Option {
+	return func(cfg *clientConfig) {
+		cfg.verify.skip = false
+		cfg.verify.backoff = backoff
+		cfg.verify.deadline = deadline
+	}
+}
+
+// WithVerifyDatabases runs listCollections on each named database after the
+// ping, failing unless the authenticated user holds the listCollections
+// privilege there. Other privileges, such as find or insert, are not
+// checked.
+func WithVerifyDatabases(names ...string) Option {
+	return func(cfg *clientConfig) {
+		cfg.verify.skip = false
+		cfg.verify.databases = append(cfg.verify.databases, names...)
+	}
+}
+
+// verifier is the part of *mongo.Client used to check a connection.
+type verifier interface {
+	Ping(ctx context.Context, rp *readpref.ReadPref) error
+	RunCommand(ctx context.Context, db string, cmd interface{}) error
+}
+
+type driverVerifier struct {
+	*mongo.Client
+}
+
+func (v driverVerifier) RunCommand(ctx context.Context, db string, cmd interface{}) error {
+	return v.Client.Database(db).RunCommand(ctx, cmd).Err()
+}
+
+func verify(ctx context.Context, v verifier, cfg verifyConfig) error {
+	if cfg.skip {
+		return nil
+	}
+
+	if err := ping(ctx, v, cfg); err != nil {
+		return err
+	}
+
+	for _, db := range cfg.databases {
+		cmd := bson.D{
+			{Key: "listCollections", Value: 1},
+			{Key: "nameOnly", Value: true},
+		}
+		if err := v.RunCommand(ctx, db, cmd); err != nil {
+			return &ConnectError{Step: StepVerifyDatabase, Database: db, Err: err}
+		}
+	}
+	return nil
+}
+
+func ping(ctx context.Context, v verifier, cfg verifyConfig) error {
+	rp := cfg.readPref
+	if rp == nil {
+		rp = readpref.Primary()
+	}
+
+	if cfg.deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.deadline)
+		defer cancel()
+	}
+
+	wait := cfg.backoff
+	for attempt := 1; ; attempt++ {
+		err := v.Ping(ctx, rp)
+		if err == nil {
+			return nil
+		}
+		if cfg.deadline <= 0 || wait <= 0 {
+			return &ConnectError{Step: StepPing, Attempts: attempt, Err: err}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return &ConnectError{Step: StepPing, Attempts: attempt, Err: err}
+		case <-timer.C:
+		}
+		wait *= 2
+	}
+}
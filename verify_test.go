@@ -0,0 +1,126 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+type fakeVerifier struct {
+	pingErrs []error
+	pings    int
+	lastRP   *readpref.ReadPref
+	dbErrs   map[string]error
+	checked  []string
+}
+
+func (v *fakeVerifier) Ping(ctx context.Context, rp *readpref.ReadPref) error {
+	v.lastRP = rp
+	v.pings++
+	if len(v.pingErrs) == 0 {
+		return nil
+	}
+	err := v.pingErrs[0]
+	v.pingErrs = v.pingErrs[1:]
+	return err
+}
+
+func (v *fakeVerifier) RunCommand(ctx context.Context, db string, cmd interface{}) error {
+	v.checked = append(v.checked, db)
+	return v.dbErrs[db]
+}
+
+func TestVerify(t *testing.T) {
+	errDown := errors.New("server selection error")
+	errAuth := errors.New("not authorized")
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		v        *fakeVerifier
+		step     string
+		pings    int
+		checked  []string
+		wantMode readpref.Mode
+	}{
+		{
+			name:     "default pings the primary once",
+			v:        &fakeVerifier{pingErrs: []error{errDown}},
+			step:     StepPing,
+			pings:    1,
+			wantMode: readpref.PrimaryMode,
+		},
+		{
+			name: "skip",
+			opts: []Option{WithoutVerification()},
+			v:    &fakeVerifier{pingErrs: []error{errDown}},
+		},
+		{
+			name:     "read preference",
+			opts:     []Option{WithPingReadPreference(readpref.Secondary())},
+			v:        &fakeVerifier{},
+			pings:    1,
+			wantMode: readpref.SecondaryMode,
+		},
+		{
+			name:     "retry until success",
+			opts:     []Option{WithPingRetry(time.Millisecond, time.Second)},
+			v:        &fakeVerifier{pingErrs: []error{errDown, errDown}},
+			pings:    3,
+			wantMode: readpref.PrimaryMode,
+		},
+		{
+			name:     "databases",
+			opts:     []Option{WithVerifyDatabases("orders", "billing")},
+			v:        &fakeVerifier{dbErrs: map[string]error{"billing": errAuth}},
+			step:     StepVerifyDatabase,
+			pings:    1,
+			checked:  []string{"orders", "billing"},
+			wantMode: readpref.PrimaryMode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verify(context.TODO(), tt.v, newClientConfig(tt.opts...).verify)
+
+			var cerr *ConnectError
+			if tt.step == "" && err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+			if tt.step != "" && (!errors.As(err, &cerr) || cerr.Step != tt.step) {
+				t.Fatalf("err = %v, want a ConnectError at step %q", err, tt.step)
+			}
+			if tt.v.pings != tt.pings {
+				t.Errorf("pings = %d, want %d", tt.v.pings, tt.pings)
+			}
+			if tt.pings > 0 && tt.v.lastRP.Mode() != tt.wantMode {
+				t.Errorf("read preference = %v, want %v", tt.v.lastRP.Mode(), tt.wantMode)
+			}
+			if len(tt.checked) > 0 && len(tt.v.checked) != len(tt.checked) {
+				t.Errorf("checked = %v, want %v", tt.v.checked, tt.checked)
+			}
+		})
+	}
+}
+
+func TestPingRetryGivesUpAtDeadline(t *testing.T) {
+	errDown := errors.New("server selection error")
+	v := &fakeVerifier{pingErrs: make([]error, 100)}
+	for i := range v.pingErrs {
+		v.pingErrs[i] = errDown
+	}
+
+	err := verify(context.TODO(), v, newClientConfig(WithPingRetry(5*time.Millisecond, 30*time.Millisecond)).verify)
+
+	var cerr *ConnectError
+	if !errors.As(err, &cerr) || !errors.Is(err, errDown) {
+		t.Fatalf("err = %v, want a ConnectError wrapping %v", err, errDown)
+	}
+	if cerr.Attempts < 2 || cerr.Attempts != v.pings {
+		t.Errorf("attempts = %d, pings = %d", cerr.Attempts, v.pings)
+	}
+}